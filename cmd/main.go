@@ -1,44 +1,214 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io/fs"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"k8s-metrics-api/docs"
+	"k8s-metrics-api/internal/audit"
 	"k8s-metrics-api/internal/config"
 	"k8s-metrics-api/internal/handlers"
 	"k8s-metrics-api/internal/k8s"
+	"k8s-metrics-api/internal/logging"
 	"k8s-metrics-api/internal/metrics"
 	"k8s-metrics-api/internal/middleware"
 )
 
 func main() {
 	cfg, err := config.New()
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
 	if err != nil {
+		fmt.Fprintln(os.Stderr, "Erro ao carregar configuração:", err)
 		os.Exit(1)
 	}
-	cfg.Logger.Info("Iniciando a API de Métricas Kubernetes...")
+	if cfg.PrintConfig {
+		if err := cfg.WriteRedacted(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "Erro ao imprimir configuração:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	cfg.Logger.Info("Iniciando a API de Métricas Kubernetes...", "configFile", cfg.ConfigFile, "logLevel", cfg.LogLevel)
 
-	k8sClient, err := k8s.NewClient(cfg.Logger)
+	k8sClients, err := k8s.NewClients(cfg.Clusters, cfg.Logger)
 	if err != nil {
 		cfg.Logger.Error("Erro ao inicializar cliente Kubernetes", "error", err)
 		os.Exit(1)
 	}
 
-	promMetrics := metrics.NewPrometheusMetrics(cfg.Logger)
-	h := handlers.New(k8sClient, promMetrics, cfg.Logger)
+	if err := metrics.ValidateCardinality(cfg.Cardinality); err != nil {
+		cfg.Logger.Error("Erro na configuração de cardinalidade", "error", err)
+		os.Exit(1)
+	}
+	// Registro próprio: apenas as métricas da aplicação e os collectors de Go/processo.
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	registry := metrics.DefaultRegistry()
+	if err := registry.RegisterCustomResources(cfg.CustomResources); err != nil {
+		cfg.Logger.Error("Erro na configuração de recursos customizados", "error", err)
+		os.Exit(1)
+	}
+	if cfg.Certificates.CertManager {
+		registry.Register(metrics.CertManagerCollector{})
+	}
+	if cfg.MetadataAllowlist.Includes(config.ResourceStatefulSets) {
+		registry.Register(metrics.StatefulSetCollector{})
+	}
+	if cfg.MetadataAllowlist.Includes(config.ResourceDaemonSets) {
+		registry.Register(metrics.DaemonSetCollector{})
+	}
+	metricOpts := []metrics.Option{
+		metrics.WithCardinality(cfg.Cardinality),
+		metrics.WithMetadataAllowlist(cfg.MetadataAllowlist),
+		metrics.WithCustomResources(cfg.CustomResources),
+	}
+	clusters := make([]handlers.Cluster, 0, len(k8sClients))
+	for _, c := range k8sClients {
+		clusters = append(clusters, handlers.Cluster{
+			Name:    c.Name,
+			Client:  c,
+			Metrics: metrics.NewClusterMetrics(reg, c.Name, cfg.Logger, metricOpts...),
+		})
+	}
+	h := handlers.NewMulti(clusters, cfg.Logger)
+	nsFilter, err := k8s.NewNamespaceFilter(cfg.Namespaces)
+	if err != nil {
+		cfg.Logger.Error("Erro ao compilar filtro de namespaces", "error", err)
+		os.Exit(1)
+	}
+	h.SetNamespaceFilter(nsFilter)
+	collectorSet, err := registry.Select(cfg.Collection.Collectors, cfg.Collection.DisabledCollectors)
+	if err != nil {
+		cfg.Logger.Error("Configuração de collectors inválida", "error", err)
+		os.Exit(1)
+	}
+	h.SetCollectors(collectorSet)
+	h.SetCollectionConfig(cfg.Collection)
+	h.SetCertificateConfig(cfg.Certificates)
+	h.SetImagePolicy(metrics.NewImagePolicy(cfg.ImagePolicy))
+	complianceRules, err := metrics.NewComplianceRules(cfg.Compliance.Rules, cfg.Compliance.DisabledRules)
+	if err != nil {
+		cfg.Logger.Error("Configuração de regras de conformidade inválida", "error", err)
+		os.Exit(1)
+	}
+	h.SetComplianceRules(complianceRules)
+
+	go cfg.Tokens.Watch(context.Background(), cfg.TokensReloadInterval, cfg.Logger)
+	reloader := config.NewReloader(cfg, reg, cfg.Logger)
+	reloader.OnReload(func(c *config.Config) {
+		f, err := k8s.NewNamespaceFilter(c.Namespaces)
+		if err != nil {
+			cfg.Logger.Error("Erro ao compilar filtro de namespaces", "error", err)
+			return
+		}
+		h.SetNamespaceFilter(f)
+	}, config.NamespaceFilterKeys...)
+	reloader.OnReload(func(c *config.Config) {
+		cs, err := registry.Select(c.Collection.Collectors, c.Collection.DisabledCollectors)
+		if err != nil {
+			cfg.Logger.Error("Configuração de collectors inválida", "error", err)
+			return
+		}
+		h.SetCollectors(cs)
+		h.SetCollectionConfig(c.Collection)
+	}, config.CollectionKeys...)
+	reloader.OnReload(func(c *config.Config) {
+		h.SetCertificateConfig(c.Certificates)
+	}, config.CertificateKeys...)
+	reloader.OnReload(func(c *config.Config) {
+		h.SetImagePolicy(metrics.NewImagePolicy(c.ImagePolicy))
+	}, config.ImagePolicyKeys...)
+	reloader.OnReload(func(c *config.Config) {
+		rules, err := metrics.NewComplianceRules(c.Compliance.Rules, c.Compliance.DisabledRules)
+		if err != nil {
+			cfg.Logger.Error("Configuração de regras de conformidade inválida", "error", err)
+			return
+		}
+		h.SetComplianceRules(rules)
+	}, config.ComplianceKeys...)
+	go reloader.Run(context.Background())
+
+	authOpts := []middleware.AuthOption{
+		middleware.WithSchemes(cfg.AuthSchemes...),
+		middleware.WithBasicUsername(cfg.BasicAuthUsername),
+	}
+	if cfg.OIDC.Enabled() {
+		jwtValidator, err := middleware.NewJWTValidator(context.Background(), cfg.OIDC)
+		if err != nil {
+			cfg.Logger.Error("Erro ao inicializar validação OIDC", "error", err)
+			os.Exit(1)
+		}
+		authOpts = append(authOpts, middleware.WithJWT(jwtValidator))
+		cfg.Logger.Info("Autenticação OIDC habilitada", "issuer", cfg.OIDC.Issuer)
+	}
+	auth := middleware.NewAuthenticator(cfg.Tokens, cfg.Logger, authOpts...)
+	logMw := middleware.LoggingMiddleware(cfg.Logger, middleware.WithHTTPMetrics(middleware.NewHTTPMetrics(reg)))
+	limiter := middleware.NewRateLimiter(cfg.RateLimit, reg, cfg.Logger)
+
+	mux := routes(h, auth, limiter, reg, cfg.Level, cfg.Logger)
+	var auditLog *slog.Logger
+	if cfg.Audit.Enabled() {
+		auditOut, err := audit.Open(cfg.Audit.Sink, cfg.Audit.MaxSizeBytes, cfg.Audit.MaxBackups)
+		if err != nil {
+			cfg.Logger.Error("Erro ao abrir log de auditoria", "sink", cfg.Audit.Sink, "error", err)
+			os.Exit(1)
+		}
+		defer auditOut.Close()
+		auditLog = logging.New(auditOut, logging.FormatJSON, nil)
+		cfg.Logger.Info("Log de auditoria habilitado", "sink", cfg.Audit.Sink)
+	}
+	handler := chain(mux, cfg.TrustedProxies, logMw, limiter, auditLog)
+
+	server := &http.Server{Addr: ":" + cfg.Port, Handler: handler}
 
-	authMw := middleware.AuthMiddleware(cfg.ExpectedAuthToken, cfg.Logger)
-	logMw := middleware.LoggingMiddleware(cfg.Logger)
+	cfg.Logger.Info("Servidor de métricas Kubernetes escutando...", "port", cfg.Port)
+	cfg.Logger.Info("Endpoints disponíveis:",
+		"metricsJSON", fmt.Sprintf("http://localhost:%s/metrics (protegido)", cfg.Port),
+		"prometheusMetrics", fmt.Sprintf("http://localhost:%s/prometheus (protegido)", cfg.Port),
+		"clusters", fmt.Sprintf("http://localhost:%s/clusters (protegido)", cfg.Port),
+		"logLevel", fmt.Sprintf("http://localhost:%s/admin/loglevel (admin)", cfg.Port),
+		"healthCheck", fmt.Sprintf("http://localhost:%s/healthz", cfg.Port),
+		"swaggerSpec", fmt.Sprintf("http://localhost:%s/swagger.yaml", cfg.Port),
+		"swaggerUI", fmt.Sprintf("http://localhost:%s/docs", cfg.Port),
+	)
 
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		cfg.Logger.Error("Erro ao iniciar o servidor HTTP", "error", err)
+		os.Exit(1)
+	}
+}
+
+// routes registra os endpoints da API. O limite por IP é aplicado em chain,
+// antes da autenticação; aqui ficam o limite por identidade e o de coletas
+// simultâneas.
+func routes(h *handlers.Handler, auth *middleware.Authenticator, limiter *middleware.RateLimiter, reg *prometheus.Registry, level *slog.LevelVar, logger *slog.Logger) *http.ServeMux {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/metrics", authMw(h.MetricsJSONHandler))
-	mux.Handle("/prometheus", authMw(func(w http.ResponseWriter, r *http.Request) { promhttp.Handler().ServeHTTP(w, r) }))
+	mux.HandleFunc("/metrics", auth.Require(config.ScopeMetricsRead)(limiter.Limit(limiter.LimitConcurrency(h.MetricsJSONHandler))))
+	mux.HandleFunc("GET /metrics/custom", auth.Require(config.ScopeMetricsRead)(limiter.Limit(limiter.LimitConcurrency(h.CustomMetricsHandler))))
+	mux.HandleFunc("GET /metrics/images", auth.Require(config.ScopeMetricsRead)(limiter.Limit(limiter.LimitConcurrency(h.ImagesHandler))))
+	mux.HandleFunc("GET /metrics/certificates", auth.Require(config.ScopeMetricsRead)(limiter.Limit(limiter.LimitConcurrency(h.CertificatesHandler))))
+	mux.HandleFunc("GET /compliance", auth.Require(config.ScopeMetricsRead)(limiter.Limit(limiter.LimitConcurrency(h.ComplianceHandler))))
+	mux.HandleFunc("GET /clusters", auth.Require(config.ScopeMetricsRead)(h.ClustersHandler))
+	mux.HandleFunc("GET /clusters/{name}/metrics", auth.Require(config.ScopeMetricsRead)(limiter.Limit(limiter.LimitConcurrency(h.ClusterMetricsHandler))))
+	mux.Handle("/prometheus", auth.Require(config.ScopePrometheusScrape)(limiter.Limit(promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP)))
 	mux.HandleFunc("/healthz", h.HealthCheckHandler)
+	logLevel := auth.Require(config.ScopeAdmin)(handlers.LogLevelHandler(level, logger))
+	mux.HandleFunc("GET /admin/loglevel", logLevel)
+	mux.HandleFunc("PUT /admin/loglevel", logLevel)
 
 	// Servir swagger.yaml estático
 	mux.HandleFunc("/swagger.yaml", func(w http.ResponseWriter, r *http.Request) {
@@ -68,20 +238,16 @@ func main() {
 <script>window.onload=()=>{fetch('/swagger.yaml',{cache:'no-store'}).then(()=>{window.ui=SwaggerUIBundle({url:'/swagger.yaml',dom_id:'#swagger-ui',deepLinking:true,tryItOutEnabled:true});});};</script>
 </body></html>`))
 	})
+	return mux
+}
 
-	server := &http.Server{Addr: ":" + cfg.Port, Handler: logMw(mux)}
-
-	cfg.Logger.Info("Servidor de métricas Kubernetes escutando...", "port", cfg.Port)
-	cfg.Logger.Info("Endpoints disponíveis:",
-		"metricsJSON", fmt.Sprintf("http://localhost:%s/metrics (protegido)", cfg.Port),
-		"prometheusMetrics", fmt.Sprintf("http://localhost:%s/prometheus (protegido)", cfg.Port),
-		"healthCheck", fmt.Sprintf("http://localhost:%s/healthz", cfg.Port),
-		"swaggerSpec", fmt.Sprintf("http://localhost:%s/swagger.yaml", cfg.Port),
-		"swaggerUI", fmt.Sprintf("http://localhost:%s/docs", cfg.Port),
-	)
-
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		cfg.Logger.Error("Erro ao iniciar o servidor HTTP", "error", err)
-		os.Exit(1)
+// chain envolve o mux com os middlewares, do mais externo ao mais interno:
+// request ID, IP de origem, log, limite por IP, auditoria (quando auditLog
+// não é nil) e registro da rota.
+func chain(mux http.Handler, trusted []*net.IPNet, logMw func(http.Handler) http.Handler, limiter *middleware.RateLimiter, auditLog *slog.Logger) http.Handler {
+	handler := middleware.RouteMiddleware(mux)
+	if auditLog != nil {
+		handler = middleware.AuditMiddleware(auditLog)(handler)
 	}
+	return middleware.RequestIDMiddleware(middleware.RealIPMiddleware(trusted)(logMw(limiter.LimitIP(handler))))
 }
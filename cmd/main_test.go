@@ -1,10 +1,24 @@
 package main
 
 import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s-metrics-api/internal/config"
+	"k8s-metrics-api/internal/handlers"
+	"k8s-metrics-api/internal/k8s"
+	"k8s-metrics-api/internal/metrics"
+	"k8s-metrics-api/internal/middleware"
 )
 
 func TestMainDependencies(t *testing.T) {
@@ -99,3 +113,80 @@ func TestEnvironmentVariables(t *testing.T) {
 		})
 	}
 }
+
+// newTestServer monta rotas e middlewares como em main, com um cluster fake e
+// o token "secret".
+func newTestServer(t *testing.T, rl config.RateLimitConfig, auditLog *slog.Logger) (http.Handler, *prometheus.Registry) {
+	t.Helper()
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	reg := prometheus.NewRegistry()
+	h := handlers.NewMulti([]handlers.Cluster{{
+		Name:    config.DefaultClusterName,
+		Client:  &k8s.Client{Clientset: fake.NewSimpleClientset()},
+		Metrics: metrics.NewClusterMetrics(reg, config.DefaultClusterName, logger),
+	}}, logger)
+	tokens, err := config.NewTokenStore("", config.Token{Name: "grafana", Value: "secret", Scopes: []string{config.ScopeMetricsRead}})
+	require.NoError(t, err)
+	auth := middleware.NewAuthenticator(tokens, logger)
+	limiter := middleware.NewRateLimiter(rl, reg, logger)
+	logMw := middleware.LoggingMiddleware(logger, middleware.WithHTTPMetrics(middleware.NewHTTPMetrics(reg)))
+	return chain(routes(h, auth, limiter, reg, new(slog.LevelVar), logger), nil, logMw, limiter, auditLog), reg
+}
+
+func TestChainThrottlesUnauthenticatedClients(t *testing.T) {
+	// Arrange
+	handler, _ := newTestServer(t, config.RateLimitConfig{IPRPS: 0.001, IPBurst: 3}, nil)
+	send := func(remoteAddr, token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/clusters", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Act
+	var codes []int
+	for range 4 {
+		codes = append(codes, send("10.0.0.1:1234", "wrong"))
+	}
+	valid := send("10.0.0.1:1234", "secret")
+	other := send("10.0.0.2:1234", "secret")
+
+	// Assert
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+	assert.Equal(t, http.StatusTooManyRequests, valid, "o bucket do IP vale também para credenciais válidas")
+	assert.Equal(t, http.StatusOK, other)
+}
+
+func TestChainLabelsRoutesWithAudit(t *testing.T) {
+	// Arrange
+	var auditOut bytes.Buffer
+	handler, reg := newTestServer(t, config.RateLimitConfig{}, slog.New(slog.NewJSONHandler(&auditOut, nil)))
+	req := httptest.NewRequest(http.MethodGet, "/clusters/default/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, auditOut.String(), `"path":"/clusters/default/metrics"`)
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	var routes []string
+	for _, f := range families {
+		if f.GetName() != "k8s_metrics_api_http_request_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "route" {
+					routes = append(routes, l.GetValue())
+				}
+			}
+		}
+	}
+	assert.Equal(t, []string{"GET /clusters/{name}/metrics"}, routes)
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxBackups      int
+		writes          int
+		expectedFiles   []string
+		unexpectedFiles []string
+	}{
+		{
+			name:          "should not rotate below limit",
+			maxBackups:    2,
+			writes:        1,
+			expectedFiles: []string{"audit.log"},
+		},
+		{
+			name:            "should rotate and keep backups",
+			maxBackups:      2,
+			writes:          4,
+			expectedFiles:   []string{"audit.log", "audit.log.1", "audit.log.2"},
+			unexpectedFiles: []string{"audit.log.3"},
+		},
+		{
+			name:            "should truncate without backups",
+			maxBackups:      0,
+			writes:          3,
+			expectedFiles:   []string{"audit.log"},
+			unexpectedFiles: []string{"audit.log.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			path := filepath.Join(dir, "audit.log")
+			w, err := NewRotatingFile(path, 20, tt.maxBackups)
+			require.NoError(t, err)
+			defer w.Close()
+
+			// Act
+			for i := 0; i < tt.writes; i++ {
+				_, err := w.Write([]byte(strings.Repeat("x", 15) + "\n"))
+				require.NoError(t, err)
+			}
+
+			// Assert
+			for _, f := range tt.expectedFiles {
+				assert.FileExists(t, filepath.Join(dir, f))
+			}
+			for _, f := range tt.unexpectedFiles {
+				assert.NoFileExists(t, filepath.Join(dir, f))
+			}
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, info.Size(), int64(20))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"net"
+	"strings"
+)
+
+// AuditConfig configura o log de auditoria de acessos autenticados.
+type AuditConfig struct {
+	// Sink é "stdout", "stderr" ou o caminho de um arquivo; vazio desabilita.
+	Sink         string
+	MaxSizeBytes int64
+	MaxBackups   int
+}
+
+// Enabled indica se o log de auditoria foi configurado.
+func (a AuditConfig) Enabled() bool { return a.Sink != "" }
+
+func loadAudit(l *loader) AuditConfig {
+	return AuditConfig{
+		Sink:         l.get("AUDIT_LOG"),
+		MaxSizeBytes: int64(l.int("AUDIT_LOG_MAX_SIZE_MB")) << 20,
+		MaxBackups:   l.int("AUDIT_LOG_MAX_BACKUPS"),
+	}
+}
+
+// ParseCIDRs interpreta uma lista de CIDRs ou IPs isolados separados por vírgula.
+func ParseCIDRs(v string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, s := range splitList(v, ",") {
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil {
+				bits := 8 * len(ip.To16())
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, &ConfigError{"CIDR inválido: " + s}
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// CardinalityConfig controla a cardinalidade das famílias de métricas.
+// DropLabels remove labels de uma família, somando as séries que passam a
+// coincidir (ex.: restarts por namespace em vez de por container). MaxSeries
+// limita as séries de cada família; o excedente vai para uma série de overflow.
+type CardinalityConfig struct {
+	DropLabels       map[string][]string
+	MaxSeries        map[string]int
+	DefaultMaxSeries int
+}
+
+// For retorna os labels descartados e o limite de séries de family.
+func (c CardinalityConfig) For(family string) (drop []string, maxSeries int) {
+	maxSeries = c.DefaultMaxSeries
+	if n, ok := c.MaxSeries[family]; ok {
+		maxSeries = n
+	}
+	return c.DropLabels[family], maxSeries
+}
+
+func loadCardinality(l *loader) CardinalityConfig {
+	c := CardinalityConfig{DefaultMaxSeries: l.int("METRICS_DEFAULT_MAX_SERIES")}
+	drop, err := parseMap("METRICS_DROP_LABELS", l.get("METRICS_DROP_LABELS"))
+	if err != nil {
+		l.fail(err)
+	}
+	if len(drop) > 0 {
+		c.DropLabels = drop
+	}
+	limits, err := parseMap("METRICS_MAX_SERIES", l.get("METRICS_MAX_SERIES"))
+	if err != nil {
+		l.fail(err)
+	}
+	for family, v := range limits {
+		n, err := strconv.Atoi(first(v))
+		if err != nil || len(v) != 1 {
+			l.fail(&ConfigError{fmt.Sprintf("METRICS_MAX_SERIES inválido para %s: %v", family, v)})
+			continue
+		}
+		if c.MaxSeries == nil {
+			c.MaxSeries = map[string]int{}
+		}
+		c.MaxSeries[family] = n
+	}
+	return c
+}
+
+func (c CardinalityConfig) validate() []error {
+	var errs []error
+	if c.DefaultMaxSeries < 0 {
+		errs = append(errs, &ConfigError{"METRICS_DEFAULT_MAX_SERIES não pode ser negativo"})
+	}
+	for _, family := range sortedMapKeys(c.MaxSeries) {
+		if c.MaxSeries[family] < 0 {
+			errs = append(errs, &ConfigError{"METRICS_MAX_SERIES negativo para " + family})
+		}
+	}
+	return errs
+}
+
+func first(v []string) string {
+	if len(v) == 0 {
+		return ""
+	}
+	return v[0]
+}
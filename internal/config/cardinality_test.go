@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCardinality(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		env         map[string]string
+		expected    CardinalityConfig
+		expectError string
+	}{
+		{
+			name: "should load limits from config file",
+			file: `
+auth:
+  token: secret
+metrics:
+  defaultMaxSeries: 1000
+  dropLabels:
+    k8s_container_restarts_total: [pod, container]
+  maxSeries:
+    k8s_pod_status_phase: 50
+`,
+			expected: CardinalityConfig{
+				DropLabels:       map[string][]string{"k8s_container_restarts_total": {"pod", "container"}},
+				MaxSeries:        map[string]int{"k8s_pod_status_phase": 50},
+				DefaultMaxSeries: 1000,
+			},
+		},
+		{
+			name: "should load limits from env",
+			file: "auth:\n  token: secret\n",
+			env:  map[string]string{"METRICS_MAX_SERIES": "k8s_node_status_ready=10;k8s_pod_status_phase=20"},
+			expected: CardinalityConfig{
+				MaxSeries: map[string]int{"k8s_node_status_ready": 10, "k8s_pod_status_phase": 20},
+			},
+		},
+		{
+			name:        "should reject non numeric limit",
+			file:        "auth:\n  token: secret\n",
+			env:         map[string]string{"METRICS_MAX_SERIES": "k8s_pod_status_phase=muitas"},
+			expectError: "METRICS_MAX_SERIES inválido para k8s_pod_status_phase",
+		},
+		{
+			name:        "should reject negative default limit",
+			file:        "auth:\n  token: secret\n",
+			env:         map[string]string{"METRICS_DEFAULT_MAX_SERIES": "-1"},
+			expectError: "METRICS_DEFAULT_MAX_SERIES",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			clearEnv(t)
+			t.Setenv("CONFIG_FILE", writeConfigFile(t, tt.file))
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// Act
+			cfg, err := Load(nil)
+
+			// Assert
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.Cardinality)
+		})
+	}
+}
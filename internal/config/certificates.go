@@ -0,0 +1,34 @@
+package config
+
+import "time"
+
+// DefaultCertificateExpiryWindow janela padrão de certificados a expirar.
+const DefaultCertificateExpiryWindow = 30 * 24 * time.Hour
+
+// CertificateKeys opções de CertificateConfig aplicáveis sem reinício.
+var CertificateKeys = []string{"TLS_EXPIRY_WINDOW"}
+
+// CertificateConfig define o monitoramento de certificados TLS. Secrets do
+// tipo kubernetes.io/tls são sempre coletados pelo collector tlssecrets; os
+// Certificates do cert-manager, apenas com CertManager.
+type CertificateConfig struct {
+	// ExpiryWindow certificados que expiram dentro da janela (ou já
+	// expirados) são listados em /metrics/certificates.
+	ExpiryWindow time.Duration
+	// CertManager registra o collector certmanager; exige reinício.
+	CertManager bool
+}
+
+func loadCertificates(l *loader) CertificateConfig {
+	return CertificateConfig{
+		ExpiryWindow: l.duration("TLS_EXPIRY_WINDOW"),
+		CertManager:  l.bool("TLS_CERT_MANAGER"),
+	}
+}
+
+func (c CertificateConfig) validate() []error {
+	if c.ExpiryWindow <= 0 {
+		return []error{&ConfigError{"TLS_EXPIRY_WINDOW deve ser positivo"}}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultClusterName nome do cluster quando nenhum arquivo de clusters é informado.
+const DefaultClusterName = "default"
+
+var clusterNameRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9_.]*[a-z0-9])?$`)
+
+// ClusterConfig descreve como conectar a um cluster. Sem kubeconfig ou
+// inCluster, usa in-cluster com fallback para as regras padrão do kubeconfig
+// ($KUBECONFIG ou ~/.kube/config). Campos de client zerados recebem os
+// padrões de KubeClientConfig; ListPageSize só quando ausente, já que zero
+// desabilita a paginação.
+type ClusterConfig struct {
+	Name         string   `json:"name"`
+	Kubeconfig   string   `json:"kubeconfig,omitempty"`
+	Context      string   `json:"context,omitempty"`
+	InCluster    bool     `json:"inCluster,omitempty"`
+	QPS          float32  `json:"qps,omitempty"`
+	Burst        int      `json:"burst,omitempty"`
+	Timeout      Duration `json:"timeout,omitempty"`
+	UserAgent    string   `json:"userAgent,omitempty"`
+	ListPageSize *int64   `json:"listPageSize,omitempty"`
+}
+
+type clustersFile struct {
+	Clusters []ClusterConfig `json:"clusters"`
+}
+
+// LoadClustersFile lê um arquivo YAML ou JSON com a lista de clusters.
+func LoadClustersFile(path string) ([]ClusterConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f clustersFile
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("arquivo de clusters %s inválido: %w", path, err)
+	}
+	if len(f.Clusters) == 0 {
+		return nil, fmt.Errorf("arquivo de clusters %s não define clusters", path)
+	}
+	if err := validateClusters(f.Clusters); err != nil {
+		return nil, fmt.Errorf("arquivo de clusters %s inválido: %w", path, err)
+	}
+	return f.Clusters, nil
+}
+
+func validateClusters(clusters []ClusterConfig) error {
+	seen := map[string]bool{}
+	for i, c := range clusters {
+		if !clusterNameRe.MatchString(c.Name) {
+			return fmt.Errorf("cluster #%d com nome inválido: %q", i, c.Name)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("nome de cluster duplicado: %s", c.Name)
+		}
+		seen[c.Name] = true
+		if c.InCluster && (c.Kubeconfig != "" || c.Context != "") {
+			return fmt.Errorf("cluster %s: inCluster não pode ser combinado com kubeconfig/context", c.Name)
+		}
+		if c.ListPageSize != nil && *c.ListPageSize < 0 {
+			return fmt.Errorf("cluster %s: listPageSize não pode ser negativo", c.Name)
+		}
+	}
+	return nil
+}
+
+// loadClusters usa CLUSTERS_FILE, a lista "clusters" do arquivo de
+// configuração ou, na ausência de ambos, um único cluster.
+func loadClusters(l *loader, kube KubeClientConfig, fromConfig []ClusterConfig) []ClusterConfig {
+	var clusters []ClusterConfig
+	switch path := l.get("CLUSTERS_FILE"); {
+	case path != "":
+		var err error
+		if clusters, err = LoadClustersFile(path); err != nil {
+			l.fail(err)
+			return nil
+		}
+	case len(fromConfig) > 0:
+		clusters = fromConfig
+	default:
+		// Kubeconfig/context globais valem apenas para o cluster único.
+		clusters = []ClusterConfig{{Name: l.get("CLUSTER_NAME"), Kubeconfig: kube.Kubeconfig, Context: kube.Context}}
+		if err := validateClusters(clusters); err != nil {
+			l.fail(&ConfigError{"CLUSTER_NAME inválido: " + err.Error()})
+			return nil
+		}
+	}
+	applyKubeDefaults(clusters, kube)
+	return clusters
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadClustersFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedNames []string
+		expectError   bool
+	}{
+		{
+			name: "should load clusters",
+			content: `
+clusters:
+  - name: prod-eu
+    kubeconfig: /etc/kube/prod.yaml
+    context: prod-eu
+  - name: local
+    inCluster: true
+`,
+			expectedNames: []string{"prod-eu", "local"},
+		},
+		{
+			name:        "should reject empty list",
+			content:     `clusters: []`,
+			expectError: true,
+		},
+		{
+			name:        "should reject duplicated names",
+			content:     `{"clusters":[{"name":"a"},{"name":"a"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "should reject names unsafe for urls",
+			content:     `{"clusters":[{"name":"Prod/EU"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "should reject inCluster with kubeconfig",
+			content:     `{"clusters":[{"name":"a","inCluster":true,"kubeconfig":"/x"}]}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), "clusters.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			// Act
+			clusters, err := LoadClustersFile(path)
+
+			// Assert
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, c := range clusters {
+				names = append(names, c.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestLoadClustersAppliesKubeDefaults(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "clusters.yaml")
+	content := `
+clusters:
+  - name: prod
+    kubeconfig: /etc/kube/prod.yaml
+    qps: 50
+    timeout: 10s
+    listPageSize: 0
+  - name: staging
+    kubeconfig: /etc/kube/staging.yaml
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv("CLUSTERS_FILE", path)
+	t.Setenv("KUBE_API_BURST", "80")
+	t.Setenv("KUBE_USER_AGENT", "ci")
+	t.Setenv("KUBE_LIST_PAGE_SIZE", "200")
+	l := &loader{}
+
+	// Act
+	clusters := loadClusters(l, loadKube(l), nil)
+
+	// Assert
+	require.Empty(t, l.errs)
+	require.Len(t, clusters, 2)
+	assert.Equal(t, float32(50), clusters[0].QPS)
+	assert.Equal(t, Duration(10*time.Second), clusters[0].Timeout)
+	assert.Equal(t, float32(DefaultKubeQPS), clusters[1].QPS)
+	assert.Equal(t, Duration(DefaultKubeTimeout), clusters[1].Timeout)
+	assert.Equal(t, 80, clusters[1].Burst)
+	assert.Equal(t, "ci", clusters[1].UserAgent)
+	// listPageSize: 0 desabilita a paginação e não recebe o padrão global.
+	require.NotNil(t, clusters[0].ListPageSize)
+	assert.Equal(t, int64(0), *clusters[0].ListPageSize)
+	require.NotNil(t, clusters[1].ListPageSize)
+	assert.Equal(t, int64(200), *clusters[1].ListPageSize)
+}
+
+func TestLoadClustersSingleCluster(t *testing.T) {
+	// Arrange
+	t.Setenv("CLUSTERS_FILE", "")
+	t.Setenv("KUBECONFIG", "/a/config:/b/config")
+	t.Setenv("KUBE_CONTEXT", "kind-kind")
+	l := &loader{}
+
+	// Act
+	clusters := loadClusters(l, loadKube(l), nil)
+
+	// Assert
+	require.Empty(t, l.errs)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, "/a/config:/b/config", clusters[0].Kubeconfig)
+	assert.Equal(t, "kind-kind", clusters[0].Context)
+	assert.Equal(t, DefaultKubeUserAgent, clusters[0].UserAgent)
+}
+
+func TestLoadKubeRejectsInvalidValues(t *testing.T) {
+	// Arrange
+	t.Setenv("KUBE_API_TIMEOUT", "soon")
+	l := &loader{}
+
+	// Act
+	loadKube(l)
+
+	// Assert
+	assert.Len(t, l.errs, 1)
+}
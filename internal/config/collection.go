@@ -0,0 +1,43 @@
+package config
+
+import "time"
+
+// Padrões da coleta.
+const (
+	DefaultCollectionWorkers = 4
+	DefaultCollectionTimeout = 15 * time.Second
+)
+
+// CollectionKeys opções de CollectionConfig, aplicáveis sem reinício.
+var CollectionKeys = []string{"COLLECTION_WORKERS", "COLLECTION_TIMEOUT", "COLLECTORS_ENABLED", "COLLECTORS_DISABLED"}
+
+// CollectionConfig define como cada coleta de cluster é executada: até
+// Workers listagens simultâneas, todas sob o mesmo prazo Timeout, com os
+// collectors em Collectors (vazio: todos) que não estão em DisabledCollectors.
+// Os nomes são validados contra o registry de collectors na inicialização.
+type CollectionConfig struct {
+	Workers            int
+	Timeout            time.Duration
+	Collectors         []string
+	DisabledCollectors []string
+}
+
+func loadCollection(l *loader) CollectionConfig {
+	return CollectionConfig{
+		Workers:            l.int("COLLECTION_WORKERS"),
+		Timeout:            l.duration("COLLECTION_TIMEOUT"),
+		Collectors:         l.list("COLLECTORS_ENABLED"),
+		DisabledCollectors: l.list("COLLECTORS_DISABLED"),
+	}
+}
+
+func (c CollectionConfig) validate() []error {
+	var errs []error
+	if c.Workers < 1 {
+		errs = append(errs, &ConfigError{"COLLECTION_WORKERS deve ser positivo"})
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, &ConfigError{"COLLECTION_TIMEOUT deve ser positivo"})
+	}
+	return errs
+}
@@ -0,0 +1,20 @@
+package config
+
+// ComplianceKeys opções de ComplianceConfig, aplicáveis sem reinício.
+var ComplianceKeys = []string{"COMPLIANCE_RULES_ENABLED", "COMPLIANCE_RULES_DISABLED"}
+
+// ComplianceConfig regras de conformidade avaliadas em /compliance e nas
+// métricas k8s_*_compliance_violations: as de Rules (vazio: todas) que não
+// estão em DisabledRules. Os nomes são validados contra as regras conhecidas
+// na inicialização.
+type ComplianceConfig struct {
+	Rules         []string
+	DisabledRules []string
+}
+
+func loadCompliance(l *loader) ComplianceConfig {
+	return ComplianceConfig{
+		Rules:         l.list("COMPLIANCE_RULES_ENABLED"),
+		DisabledRules: l.list("COMPLIANCE_RULES_DISABLED"),
+	}
+}
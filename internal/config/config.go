@@ -1,43 +1,263 @@
 package config
 
 import (
-	"flag"
+	"errors"
+	"io"
 	"log/slog"
+	"net"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"k8s-metrics-api/internal/logging"
+)
+
+// Esquemas de autenticação HTTP aceitos.
+const (
+	AuthSchemeBearer = "bearer"
+	AuthSchemeBasic  = "basic"
 )
 
+// DefaultTokensReloadInterval intervalo padrão de verificação do arquivo de tokens.
+const DefaultTokensReloadInterval = 30 * time.Second
+
+const redacted = "[REDACTED]"
+
+// Provider expõe a configuração básica da aplicação.
+type Provider interface {
+	GetPort() string
+	GetAuthToken() string
+	GetLogLevel() string
+	Validate() error
+}
+
+var _ Provider = (*Config)(nil)
+
 // Config contém configurações principais da aplicação.
 type Config struct {
-	Port              string
-	ExpectedAuthToken string
-	Logger            *slog.Logger
+	Port                 string
+	LogLevel             string
+	LogFormat            string
+	ExpectedAuthToken    string
+	TokensFile           string
+	TokensReloadInterval time.Duration
+	Tokens               *TokenStore
+	OIDC                 OIDCConfig
+	AuthSchemes          []string
+	BasicAuthUsername    string
+	RateLimit            RateLimitConfig
+	Audit                AuditConfig
+	TrustedProxies       []*net.IPNet
+	Namespaces           NamespaceFilterConfig
+	Collection           CollectionConfig
+	Cardinality          CardinalityConfig
+	MetadataAllowlist    MetadataAllowlistConfig
+	CustomResources      []CustomResourceConfig
+	Certificates         CertificateConfig
+	ImagePolicy          ImagePolicyConfig
+	Compliance           ComplianceConfig
+	Kube                 KubeClientConfig
+	Clusters             []ClusterConfig
+	Logger               *slog.Logger
+	// Level controla o nível do Logger e pode ser alterado em tempo de execução.
+	Level *slog.LevelVar
+
+	// ConfigFile arquivo YAML carregado, se houver.
+	ConfigFile string
+	// WatchInterval intervalo de verificação do arquivo de configuração; zero desabilita.
+	WatchInterval time.Duration
+	// PrintConfig indica que --print-config foi informado.
+	PrintConfig bool
+
+	args     []string
+	resolved map[string]string
 }
 
-// New carrega a configuração a partir de flags e variáveis de ambiente.
+// New carrega a configuração a partir dos argumentos do processo.
 func New() (*Config, error) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	return Load(os.Args[1:])
+}
+
+// Load carrega a configuração com a precedência flags > variáveis de ambiente
+// > arquivo de configuração (--config ou CONFIG_FILE) > padrões. Todos os
+// problemas encontrados são retornados juntos em um *ValidationError.
+func Load(args []string) (*Config, error) {
+	opts, err := parseFlags(args, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	l := &loader{flags: opts.values}
+	path := opts.configFile
+	if path == "" {
+		path = strings.TrimSpace(os.Getenv("CONFIG_FILE"))
+	}
+	var fileClusters []ClusterConfig
+	if path != "" {
+		fileClusters = l.readFile(path)
+	}
+
+	trustedProxies, err := ParseCIDRs(l.get("TRUSTED_PROXIES"))
+	if err != nil {
+		l.fail(&ConfigError{"TRUSTED_PROXIES: " + err.Error()})
+	}
+	kube := loadKube(l)
+	cfg := &Config{
+		Port:                 l.get("PORT"),
+		LogLevel:             l.get("LOG_LEVEL"),
+		LogFormat:            strings.ToLower(l.get("LOG_FORMAT")),
+		ExpectedAuthToken:    l.get("EXPECTED_AUTH_TOKEN"),
+		TokensFile:           l.get("AUTH_TOKENS_FILE"),
+		TokensReloadInterval: l.duration("AUTH_TOKENS_RELOAD_INTERVAL"),
+		OIDC:                 loadOIDC(l),
+		AuthSchemes:          splitList(strings.ToLower(l.get("AUTH_SCHEMES")), ","),
+		BasicAuthUsername:    l.get("AUTH_BASIC_USERNAME"),
+		RateLimit:            loadRateLimit(l),
+		Audit:                loadAudit(l),
+		TrustedProxies:       trustedProxies,
+		Namespaces:           loadNamespaceFilter(l),
+		Collection:           loadCollection(l),
+		Cardinality:          loadCardinality(l),
+		MetadataAllowlist:    loadMetadataAllowlist(l),
+		CustomResources:      loadCustomResources(l),
+		Certificates:         loadCertificates(l),
+		ImagePolicy:          loadImagePolicy(l),
+		Compliance:           loadCompliance(l),
+		Kube:                 kube,
+		Clusters:             loadClusters(l, kube, fileClusters),
+		ConfigFile:           path,
+		WatchInterval:        l.duration("CONFIG_WATCH_INTERVAL"),
+		PrintConfig:          opts.printConfig,
+		args:                 args,
+		resolved:             l.resolved(),
+	}
+
+	errs := l.errs
+	var ve *ValidationError
+	if errors.As(cfg.Validate(), &ve) {
+		errs = append(errs, ve.Errs...)
+	}
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errs: errs}
 	}
 
-	expectedToken := strings.TrimSpace(os.Getenv("EXPECTED_AUTH_TOKEN"))
-	if expectedToken == "" {
-		return nil, ErrMissingAuthToken
+	// O token legado continua aceito com acesso total.
+	var static []Token
+	if cfg.ExpectedAuthToken != "" {
+		static = append(static, Token{Name: "default", Value: cfg.ExpectedAuthToken, Scopes: []string{ScopeAdmin}})
+	}
+	if cfg.Tokens, err = NewTokenStore(cfg.TokensFile, static...); err != nil {
+		return nil, err
 	}
 
-	// Flags opcionais (mantidas para extensão futura)
-	_ = flag.CommandLine.Parse([]string{})
+	cfg.Level = new(slog.LevelVar)
+	_ = cfg.Level.UnmarshalText([]byte(cfg.LogLevel))
+	cfg.Logger = logging.New(os.Stdout, cfg.LogFormat, cfg.Level)
+	return cfg, nil
+}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+// Validate verifica a consistência da configuração e agrega todos os problemas.
+func (c *Config) Validate() error {
+	var errs []error
+	if p, err := strconv.Atoi(c.Port); err != nil || p < 1 || p > 65535 {
+		errs = append(errs, &ConfigError{"PORT inválido: " + c.Port})
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, &ConfigError{"LOG_LEVEL inválido: " + c.LogLevel})
+	}
+	if c.LogFormat != logging.FormatJSON && c.LogFormat != logging.FormatText {
+		errs = append(errs, &ConfigError{"LOG_FORMAT inválido: " + c.LogFormat})
+	}
+	if c.ExpectedAuthToken == "" && c.TokensFile == "" && !c.OIDC.Enabled() {
+		errs = append(errs, ErrMissingAuthToken)
+	}
+	if len(c.AuthSchemes) == 0 {
+		errs = append(errs, &ConfigError{"AUTH_SCHEMES não pode ser vazio"})
+	}
+	for _, sc := range c.AuthSchemes {
+		if sc != AuthSchemeBearer && sc != AuthSchemeBasic {
+			errs = append(errs, &ConfigError{"AUTH_SCHEMES com esquema desconhecido: " + sc})
+		}
+	}
+	errs = append(errs, c.OIDC.validate()...)
+	errs = append(errs, c.Namespaces.validate()...)
+	errs = append(errs, c.Collection.validate()...)
+	errs = append(errs, c.Certificates.validate()...)
+	errs = append(errs, c.ImagePolicy.validate()...)
+	errs = append(errs, c.Cardinality.validate()...)
+	errs = append(errs, c.MetadataAllowlist.validate()...)
+	if err := validateClusters(c.Clusters); err != nil {
+		errs = append(errs, &ConfigError{"clusters inválido: " + err.Error()})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errs: errs}
+}
 
-	return &Config{Port: port, ExpectedAuthToken: expectedToken, Logger: logger}, nil
+// GetPort retorna a porta HTTP.
+func (c *Config) GetPort() string { return c.Port }
+
+// GetAuthToken retorna o token legado (EXPECTED_AUTH_TOKEN).
+func (c *Config) GetAuthToken() string { return c.ExpectedAuthToken }
+
+// GetLogLevel retorna o nível de log configurado.
+func (c *Config) GetLogLevel() string { return c.LogLevel }
+
+// WriteRedacted escreve a configuração efetiva em YAML, no formato do arquivo
+// de configuração, com segredos ocultados.
+func (c *Config) WriteRedacted(w io.Writer) error {
+	root := map[string]any{}
+	for _, s := range settings {
+		v := c.resolved[s.Env]
+		if v == "" {
+			continue
+		}
+		var out any = redacted
+		if !s.Secret {
+			out = printValue(s, v)
+		}
+		node := root
+		parts := strings.Split(s.Path, ".")
+		for _, p := range parts[:len(parts)-1] {
+			next, ok := node[p].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				node[p] = next
+			}
+			node = next
+		}
+		node[parts[len(parts)-1]] = out
+	}
+	root["clusters"] = c.Clusters
+	b, err := yaml.Marshal(root)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
 }
 
 // ErrMissingAuthToken indica ausência de token.
-var ErrMissingAuthToken = &ConfigError{"EXPECTED_AUTH_TOKEN não definido"}
+var ErrMissingAuthToken = &ConfigError{"EXPECTED_AUTH_TOKEN, AUTH_TOKENS_FILE ou OIDC_ISSUER não definido"}
 
 // ConfigError erro simples de config.
 type ConfigError struct{ Msg string }
 
 func (e *ConfigError) Error() string { return e.Msg }
+
+// ValidationError agrega os problemas encontrados ao carregar a configuração.
+type ValidationError struct{ Errs []error }
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, "  - "+err.Error())
+	}
+	return "configuração inválida:\n" + strings.Join(msgs, "\n")
+}
+
+func (e *ValidationError) Unwrap() []error { return e.Errs }
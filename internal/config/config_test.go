@@ -0,0 +1,287 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const configYAML = `
+server:
+  port: 9000
+log:
+  level: debug
+auth:
+  token: file-secret
+  schemes: [bearer, basic]
+rateLimit:
+  identityRPS: 2.5
+kubernetes:
+  context: kind-kind
+  timeout: 10s
+oidc:
+  groupNamespaces:
+    team-a: [app-a, shared]
+metrics:
+  certificates:
+    certManager: true
+`
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// clearEnv remove variáveis do ambiente de execução que alterariam o resultado.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	for _, s := range settings {
+		t.Setenv(s.Env, "")
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name             string
+		env              map[string]string
+		args             []string
+		expectedPort     string
+		expectedLogLevel string
+		expectedToken    string
+	}{
+		{
+			name:             "should use config file values",
+			expectedPort:     "9000",
+			expectedLogLevel: "debug",
+			expectedToken:    "file-secret",
+		},
+		{
+			name:             "should let env override config file",
+			env:              map[string]string{"PORT": "9100", "EXPECTED_AUTH_TOKEN": "env-secret"},
+			expectedPort:     "9100",
+			expectedLogLevel: "debug",
+			expectedToken:    "env-secret",
+		},
+		{
+			name:             "should let flags override env",
+			env:              map[string]string{"PORT": "9100"},
+			args:             []string{"--port", "9200", "--log-level", "warn"},
+			expectedPort:     "9200",
+			expectedLogLevel: "warn",
+			expectedToken:    "file-secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			clearEnv(t)
+			t.Setenv("CONFIG_FILE", writeConfigFile(t, configYAML))
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// Act
+			cfg, err := Load(tt.args)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPort, cfg.GetPort())
+			assert.Equal(t, tt.expectedLogLevel, cfg.GetLogLevel())
+			assert.Equal(t, tt.expectedToken, cfg.GetAuthToken())
+			assert.Equal(t, []string{AuthSchemeBearer, AuthSchemeBasic}, cfg.AuthSchemes)
+			assert.Equal(t, 2.5, cfg.RateLimit.IdentityRPS)
+			assert.Equal(t, 10*time.Second, cfg.Kube.Timeout)
+			assert.Equal(t, "kind-kind", cfg.Clusters[0].Context)
+			assert.Equal(t, map[string][]string{"team-a": {"app-a", "shared"}}, cfg.OIDC.GroupNamespaces)
+			assert.True(t, cfg.Certificates.CertManager)
+		})
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	// Arrange
+	clearEnv(t)
+	t.Setenv("EXPECTED_AUTH_TOKEN", "secret")
+
+	// Act
+	cfg, err := Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "8080", cfg.Port)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, "json", cfg.LogFormat)
+	assert.Equal(t, DefaultTokensReloadInterval, cfg.TokensReloadInterval)
+	assert.Equal(t, []string{AuthSchemeBearer}, cfg.AuthSchemes)
+	assert.Equal(t, RateLimitConfig{IdentityRPS: 1, IdentityBurst: 5, IPRPS: 5, IPBurst: 10, MaxConcurrentCollections: 4}, cfg.RateLimit)
+	require.Len(t, cfg.Clusters, 1)
+	assert.Equal(t, DefaultClusterName, cfg.Clusters[0].Name)
+	require.NotNil(t, cfg.Clusters[0].ListPageSize)
+	assert.Equal(t, int64(DefaultKubeListPageSize), *cfg.Clusters[0].ListPageSize)
+	assert.Equal(t, CollectionConfig{Workers: DefaultCollectionWorkers, Timeout: DefaultCollectionTimeout}, cfg.Collection)
+	assert.Equal(t, CertificateConfig{ExpiryWindow: DefaultCertificateExpiryWindow}, cfg.Certificates)
+	assert.Equal(t, ImagePolicyConfig{DisallowedTags: []string{"latest"}}, cfg.ImagePolicy)
+	assert.Equal(t, ComplianceConfig{}, cfg.Compliance)
+}
+
+func TestLoadAggregatesErrors(t *testing.T) {
+	// Arrange
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, `
+server:
+  port: "oito"
+  colour: blue
+kubernetes:
+  qps: fast
+`))
+	t.Setenv("AUTH_SCHEMES", "digest")
+	t.Setenv("LOG_FORMAT", "xml")
+
+	// Act
+	cfg, err := Load([]string{"--log-level", "verbose"})
+
+	// Assert
+	assert.Nil(t, cfg)
+	var ve *ValidationError
+	require.ErrorAs(t, err, &ve)
+	assert.Len(t, ve.Errs, 7)
+	assert.ErrorIs(t, err, ErrMissingAuthToken)
+	for _, want := range []string{"server.port", "server.colour", "kubernetes.qps", "LOG_LEVEL", "LOG_FORMAT", "AUTH_SCHEMES"} {
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestLoadClustersFromConfigFile(t *testing.T) {
+	// Arrange
+	clearEnv(t)
+	t.Setenv("EXPECTED_AUTH_TOKEN", "secret")
+	path := writeConfigFile(t, `
+kubernetes:
+  qps: 7
+clusters:
+  - name: prod
+    kubeconfig: /etc/kube/prod.yaml
+  - name: local
+    inCluster: true
+`)
+
+	// Act
+	cfg, err := Load([]string{"--config", path})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, cfg.Clusters, 2)
+	assert.Equal(t, "prod", cfg.Clusters[0].Name)
+	assert.Equal(t, float32(7), cfg.Clusters[1].QPS)
+	assert.Equal(t, path, cfg.ConfigFile)
+}
+
+func TestLoadRejectsUnknownFlag(t *testing.T) {
+	// Arrange
+	clearEnv(t)
+
+	// Act
+	_, err := parseFlags([]string{"--nope"}, &bytes.Buffer{})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestWriteRedacted(t *testing.T) {
+	// Arrange
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, configYAML))
+	cfg, err := Load([]string{"--print-config"})
+	require.NoError(t, err)
+	var out bytes.Buffer
+
+	// Act
+	err = cfg.WriteRedacted(&out)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, cfg.PrintConfig)
+	assert.NotContains(t, out.String(), "file-secret")
+	assert.Contains(t, out.String(), "token: '[REDACTED]'")
+	assert.Contains(t, out.String(), "port: 9000")
+	assert.Contains(t, out.String(), "timeout: 10s")
+
+	// A saída deve ser aceita como arquivo de configuração.
+	l := &loader{}
+	l.readFile(writeConfigFile(t, out.String()))
+	assert.Empty(t, l.errs)
+}
+
+func TestValidationErrorUnwrap(t *testing.T) {
+	// Arrange
+	err := &ValidationError{Errs: []error{ErrMissingAuthToken, &ConfigError{"x"}}}
+
+	// Act
+	is := errors.Is(err, ErrMissingAuthToken)
+
+	// Assert
+	assert.True(t, is)
+	assert.Equal(t, "configuração inválida:\n  - "+ErrMissingAuthToken.Msg+"\n  - x", err.Error())
+}
+
+func TestLoadNamespaceFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		expected    NamespaceFilterConfig
+		expectError string
+	}{
+		{
+			name: "should load filters",
+			env: map[string]string{
+				"NAMESPACE_EXCLUDE":       "kube-system, kube-public",
+				"NAMESPACE_EXCLUDE_REGEX": "ci-.*",
+				"POD_LABEL_SELECTOR":      "app!=debug",
+			},
+			expected: NamespaceFilterConfig{Exclude: []string{"kube-system", "kube-public"}, ExcludeRegex: "ci-.*", PodLabelSelector: "app!=debug"},
+		},
+		{
+			name:        "should reject invalid regex",
+			env:         map[string]string{"NAMESPACE_INCLUDE_REGEX": "team-("},
+			expectError: "NAMESPACE_INCLUDE_REGEX",
+		},
+		{
+			name:        "should reject invalid label selector",
+			env:         map[string]string{"NAMESPACE_LABEL_SELECTOR": "team in platform"},
+			expectError: "NAMESPACE_LABEL_SELECTOR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			clearEnv(t)
+			t.Setenv("EXPECTED_AUTH_TOKEN", "secret")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// Act
+			cfg, err := Load(nil)
+
+			// Assert
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.Namespaces)
+		})
+	}
+}
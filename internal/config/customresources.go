@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+var metricNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// reservedCustomLabels labels presentes em todas as séries de recursos customizados.
+var reservedCustomLabels = map[string]bool{"cluster": true, "namespace": true, "name": true}
+
+// CustomResourceConfig descreve as métricas de um recurso customizado, no
+// estilo do custom resource state do kube-state-metrics. Caminhos de campo
+// são separados por ponto e aceitam seletores de lista, como
+// "status.conditions[type=Ready].status" ou "spec.containers[0].image".
+type CustomResourceConfig struct {
+	// Name identifica o collector e compõe o nome das famílias
+	// (k8s_custom_<name>_<metric>).
+	Name     string `json:"name"`
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+	// ClusterScoped indica recurso não namespaced.
+	ClusterScoped bool `json:"clusterScoped,omitempty"`
+	// Labels label Prometheus -> caminho do campo, acrescentados a todas as
+	// séries além de namespace e name.
+	Labels  map[string]string    `json:"labels,omitempty"`
+	Metrics []CustomMetricConfig `json:"metrics"`
+}
+
+// CustomMetricConfig gauge extraído de um campo. Números e booleanos são
+// usados diretamente; strings são convertidas por ValueMap ou, sem ele,
+// "True"/"False", quantidades ("500m", "1Gi") e horários RFC 3339 (segundos
+// Unix). Objetos sem o campo ou com valor não convertível não geram série.
+type CustomMetricConfig struct {
+	Name     string             `json:"name"`
+	Help     string             `json:"help,omitempty"`
+	Path     string             `json:"path"`
+	ValueMap map[string]float64 `json:"valueMap,omitempty"`
+}
+
+type customResourcesFile struct {
+	CustomResources []CustomResourceConfig `json:"customResources"`
+}
+
+// LoadCustomResourcesFile lê um arquivo YAML ou JSON com os recursos customizados.
+func LoadCustomResourcesFile(path string) ([]CustomResourceConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f customResourcesFile
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("arquivo de recursos customizados %s inválido: %w", path, err)
+	}
+	if err := validateCustomResources(f.CustomResources); err != nil {
+		return nil, fmt.Errorf("arquivo de recursos customizados %s inválido: %w", path, err)
+	}
+	return f.CustomResources, nil
+}
+
+func loadCustomResources(l *loader) []CustomResourceConfig {
+	path := l.get("CUSTOM_RESOURCES_FILE")
+	if path == "" {
+		return nil
+	}
+	crs, err := LoadCustomResourcesFile(path)
+	if err != nil {
+		l.fail(&ConfigError{"CUSTOM_RESOURCES_FILE: " + err.Error()})
+	}
+	return crs
+}
+
+func validateCustomResources(crs []CustomResourceConfig) error {
+	seen := map[string]bool{}
+	for i, cr := range crs {
+		if !metricNameRe.MatchString(cr.Name) {
+			return fmt.Errorf("recurso #%d com nome inválido: %q", i, cr.Name)
+		}
+		if seen[cr.Name] {
+			return fmt.Errorf("nome de recurso duplicado: %s", cr.Name)
+		}
+		seen[cr.Name] = true
+		if cr.Version == "" || cr.Resource == "" {
+			return fmt.Errorf("recurso %s: version e resource são obrigatórios", cr.Name)
+		}
+		if len(cr.Metrics) == 0 {
+			return fmt.Errorf("recurso %s não define metrics", cr.Name)
+		}
+		for label, path := range cr.Labels {
+			if !metricNameRe.MatchString(label) || reservedCustomLabels[label] {
+				return fmt.Errorf("recurso %s: label inválido: %q", cr.Name, label)
+			}
+			if path == "" {
+				return fmt.Errorf("recurso %s: label %s sem caminho", cr.Name, label)
+			}
+		}
+		metrics := map[string]bool{}
+		for _, m := range cr.Metrics {
+			if !metricNameRe.MatchString(m.Name) {
+				return fmt.Errorf("recurso %s: métrica com nome inválido: %q", cr.Name, m.Name)
+			}
+			if metrics[m.Name] {
+				return fmt.Errorf("recurso %s: métrica duplicada: %s", cr.Name, m.Name)
+			}
+			metrics[m.Name] = true
+			if m.Path == "" {
+				return fmt.Errorf("recurso %s: métrica %s sem caminho", cr.Name, m.Name)
+			}
+		}
+	}
+	return nil
+}
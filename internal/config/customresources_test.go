@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCustomResourcesFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError string
+	}{
+		{
+			name: "should load resources",
+			content: `
+customResources:
+  - name: certificate
+    group: cert-manager.io
+    version: v1
+    resource: certificates
+    labels:
+      issuer: spec.issuerRef.name
+    metrics:
+      - name: expiration_timestamp_seconds
+        path: status.notAfter
+      - name: ready
+        path: status.conditions[type=Ready].status
+`,
+		},
+		{name: "should reject unknown fields", content: "customResources:\n  - name: x\n    kind: X\n", expectError: "kind"},
+		{name: "should reject resources without metrics", content: "customResources:\n  - name: x\n    version: v1\n    resource: xs\n", expectError: "metrics"},
+		{name: "should reject reserved labels", content: "customResources:\n  - name: x\n    version: v1\n    resource: xs\n    labels:\n      namespace: spec.ns\n    metrics:\n      - name: m\n        path: spec.m\n", expectError: "namespace"},
+		{name: "should reject duplicated names", content: "customResources:\n  - {name: x, version: v1, resource: xs, metrics: [{name: m, path: spec.m}]}\n  - {name: x, version: v1, resource: ys, metrics: [{name: m, path: spec.m}]}\n", expectError: "duplicado"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := writeConfigFile(t, tt.content)
+
+			// Act
+			crs, err := LoadCustomResourcesFile(path)
+
+			// Assert
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, crs, 1)
+			assert.Equal(t, "cert-manager.io", crs[0].Group)
+			assert.Equal(t, map[string]string{"issuer": "spec.issuerRef.name"}, crs[0].Labels)
+			assert.Len(t, crs[0].Metrics, 2)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// NamespaceFilterKeys opções de NamespaceFilterConfig, aplicáveis sem reinício.
+var NamespaceFilterKeys = []string{
+	"NAMESPACE_INCLUDE", "NAMESPACE_EXCLUDE",
+	"NAMESPACE_INCLUDE_REGEX", "NAMESPACE_EXCLUDE_REGEX",
+	"NAMESPACE_LABEL_SELECTOR", "POD_LABEL_SELECTOR",
+}
+
+// NamespaceFilterConfig define quais namespaces e pods são coletados. Um
+// namespace é coletado quando está em Include ou casa com IncludeRegex (ambos
+// vazios aceitam todos), não está em Exclude, não casa com ExcludeRegex e
+// satisfaz LabelSelector. As expressões regulares precisam casar o nome inteiro.
+type NamespaceFilterConfig struct {
+	Include          []string
+	Exclude          []string
+	IncludeRegex     string
+	ExcludeRegex     string
+	LabelSelector    string
+	PodLabelSelector string
+}
+
+func loadNamespaceFilter(l *loader) NamespaceFilterConfig {
+	return NamespaceFilterConfig{
+		Include:          l.list("NAMESPACE_INCLUDE"),
+		Exclude:          l.list("NAMESPACE_EXCLUDE"),
+		IncludeRegex:     l.get("NAMESPACE_INCLUDE_REGEX"),
+		ExcludeRegex:     l.get("NAMESPACE_EXCLUDE_REGEX"),
+		LabelSelector:    l.get("NAMESPACE_LABEL_SELECTOR"),
+		PodLabelSelector: l.get("POD_LABEL_SELECTOR"),
+	}
+}
+
+func (f NamespaceFilterConfig) validate() []error {
+	var errs []error
+	for _, re := range [][2]string{{"NAMESPACE_INCLUDE_REGEX", f.IncludeRegex}, {"NAMESPACE_EXCLUDE_REGEX", f.ExcludeRegex}} {
+		if _, err := regexp.Compile(re[1]); err != nil {
+			errs = append(errs, &ConfigError{re[0] + " inválido: " + err.Error()})
+		}
+	}
+	for _, sel := range [][2]string{{"NAMESPACE_LABEL_SELECTOR", f.LabelSelector}, {"POD_LABEL_SELECTOR", f.PodLabelSelector}} {
+		if _, err := labels.Parse(sel[1]); err != nil {
+			errs = append(errs, &ConfigError{sel[0] + " inválido: " + err.Error()})
+		}
+	}
+	return errs
+}
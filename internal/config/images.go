@@ -0,0 +1,33 @@
+package config
+
+import "strings"
+
+// ImagePolicyKeys opções de ImagePolicyConfig, aplicáveis sem reinício.
+var ImagePolicyKeys = []string{"IMAGE_ALLOWED_REGISTRIES", "IMAGE_DISALLOWED_TAGS"}
+
+// ImagePolicyConfig política de imagens de containers. Pods com imagens fora
+// da política são contados em k8s_image_policy_violations.
+type ImagePolicyConfig struct {
+	// AllowedRegistries registries (ex.: "ghcr.io") ou prefixos de
+	// repositório (ex.: "ghcr.io/acme") permitidos; vazio permite todos.
+	AllowedRegistries []string
+	// DisallowedTags tags proibidas, ex.: "latest".
+	DisallowedTags []string
+}
+
+func loadImagePolicy(l *loader) ImagePolicyConfig {
+	return ImagePolicyConfig{
+		AllowedRegistries: l.list("IMAGE_ALLOWED_REGISTRIES"),
+		DisallowedTags:    l.list("IMAGE_DISALLOWED_TAGS"),
+	}
+}
+
+func (c ImagePolicyConfig) validate() []error {
+	var errs []error
+	for _, r := range c.AllowedRegistries {
+		if strings.HasSuffix(r, "/") || strings.ContainsAny(r, "@ ") {
+			errs = append(errs, &ConfigError{"IMAGE_ALLOWED_REGISTRIES inválido: " + r})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,63 @@
+package config
+
+import "time"
+
+// Valores padrão do client da API do Kubernetes.
+const (
+	DefaultKubeQPS       = 20
+	DefaultKubeBurst     = 40
+	DefaultKubeTimeout   = 30 * time.Second
+	DefaultKubeUserAgent = "k8s-metrics-api"
+	// DefaultKubeListPageSize objetos por página nas chamadas List.
+	DefaultKubeListPageSize = 500
+)
+
+// KubeClientConfig define os padrões de conexão aplicados a todos os clusters
+// que não os sobrescrevem individualmente.
+type KubeClientConfig struct {
+	// Kubeconfig aceita um ou mais caminhos separados por os.PathListSeparator,
+	// como a variável KUBECONFIG.
+	Kubeconfig string
+	Context    string
+	QPS        float32
+	Burst      int
+	Timeout    time.Duration
+	UserAgent  string
+	// ListPageSize objetos por página nas chamadas List; zero desabilita a paginação.
+	ListPageSize int64
+}
+
+func loadKube(l *loader) KubeClientConfig {
+	return KubeClientConfig{
+		Kubeconfig:   l.get("KUBECONFIG"),
+		Context:      l.get("KUBE_CONTEXT"),
+		QPS:          float32(l.float("KUBE_API_QPS")),
+		Burst:        l.int("KUBE_API_BURST"),
+		Timeout:      l.duration("KUBE_API_TIMEOUT"),
+		UserAgent:    l.get("KUBE_USER_AGENT"),
+		ListPageSize: int64(l.int("KUBE_LIST_PAGE_SIZE")),
+	}
+}
+
+// applyKubeDefaults completa os campos não definidos de cada cluster.
+func applyKubeDefaults(clusters []ClusterConfig, k KubeClientConfig) {
+	for i := range clusters {
+		c := &clusters[i]
+		if c.QPS == 0 {
+			c.QPS = k.QPS
+		}
+		if c.Burst == 0 {
+			c.Burst = k.Burst
+		}
+		if c.Timeout == 0 {
+			c.Timeout = Duration(k.Timeout)
+		}
+		if c.UserAgent == "" {
+			c.UserAgent = k.UserAgent
+		}
+		if c.ListPageSize == nil {
+			size := k.ListPageSize
+			c.ListPageSize = &size
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// loader resolve cada opção com a precedência flag > variável de ambiente >
+// arquivo de configuração > padrão, acumulando os erros encontrados.
+type loader struct {
+	flags map[string]string
+	file  map[string]string
+	errs  []error
+}
+
+// get retorna o valor resolvido da opção. Variáveis de ambiente vazias são
+// tratadas como não definidas.
+func (l *loader) get(key string) string {
+	if v, ok := l.flags[key]; ok {
+		return strings.TrimSpace(v)
+	}
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	if v, ok := l.file[key]; ok {
+		return v
+	}
+	return settingByEnv[key].Default
+}
+
+func (l *loader) fail(err error) { l.errs = append(l.errs, err) }
+
+func (l *loader) invalid(key, v string) { l.fail(&ConfigError{key + " inválido: " + v}) }
+
+func (l *loader) int(key string) int {
+	v := l.get(key)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		l.invalid(key, v)
+		return 0
+	}
+	return n
+}
+
+func (l *loader) float(key string) float64 {
+	v := l.get(key)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		l.invalid(key, v)
+		return 0
+	}
+	return f
+}
+
+func (l *loader) duration(key string) time.Duration {
+	v := l.get(key)
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		l.invalid(key, v)
+		return 0
+	}
+	return d
+}
+
+func (l *loader) bool(key string) bool {
+	v := l.get(key)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.invalid(key, v)
+		return false
+	}
+	return b
+}
+
+func (l *loader) list(key string) []string { return splitList(l.get(key), ",") }
+
+// resolved retorna o valor efetivo de todas as opções.
+func (l *loader) resolved() map[string]string {
+	out := make(map[string]string, len(settings))
+	for _, s := range settings {
+		out[s.Env] = l.get(s.Env)
+	}
+	return out
+}
+
+// cliOptions resultado da leitura da linha de comando.
+type cliOptions struct {
+	values      map[string]string
+	configFile  string
+	printConfig bool
+}
+
+// parseFlags define uma flag por opção, além de --config e --print-config.
+// Apenas flags informadas explicitamente entram na precedência.
+func parseFlags(args []string, out io.Writer) (cliOptions, error) {
+	opts := cliOptions{values: map[string]string{}}
+	fs := flag.NewFlagSet("k8s-metrics-api", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.StringVar(&opts.configFile, "config", "", "arquivo de configuração YAML (CONFIG_FILE)")
+	fs.BoolVar(&opts.printConfig, "print-config", false, "imprime a configuração efetiva, sem segredos, e encerra")
+	for _, s := range settings {
+		fs.String(s.flagName(), "", s.Help+" ("+s.Env+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, &ConfigError{"argumentos inesperados: " + strings.Join(fs.Args(), " ")}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if s, ok := settingByFlag[f.Name]; ok {
+			opts.values[s.Env] = f.Value.String()
+		}
+	})
+	return opts, nil
+}
+
+// readFile lê o arquivo de configuração YAML. Campos desconhecidos ou com
+// tipo incorreto são registrados como erro.
+func (l *loader) readFile(path string) []ClusterConfig {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		l.fail(&ConfigError{"arquivo de configuração: " + err.Error()})
+		return nil
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		l.fail(&ConfigError{fmt.Sprintf("arquivo de configuração %s inválido: %v", path, err)})
+		return nil
+	}
+	l.file = map[string]string{}
+	var clusters []ClusterConfig
+	if raw, ok := doc["clusters"]; ok {
+		delete(doc, "clusters")
+		clusters = l.fileClusters(raw)
+	}
+	l.flatten("", doc)
+	return clusters
+}
+
+func (l *loader) flatten(prefix string, m map[string]any) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if s, ok := settingByPath[path]; ok {
+			if v, ok := fileValue(s, m[k]); ok {
+				l.file[s.Env] = v
+			} else {
+				l.fail(&ConfigError{fmt.Sprintf("%s: esperado %s", path, s.Kind)})
+			}
+			continue
+		}
+		if sub, ok := m[k].(map[string]any); ok && sections[path] {
+			l.flatten(path, sub)
+			continue
+		}
+		l.fail(&ConfigError{"campo desconhecido no arquivo de configuração: " + path})
+	}
+}
+
+func (l *loader) fileClusters(raw any) []ClusterConfig {
+	b, _ := json.Marshal(raw)
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var clusters []ClusterConfig
+	if err := dec.Decode(&clusters); err != nil {
+		l.fail(&ConfigError{"clusters inválido: " + err.Error()})
+		return nil
+	}
+	if err := validateClusters(clusters); err != nil {
+		l.fail(&ConfigError{"clusters inválido: " + err.Error()})
+		return nil
+	}
+	return clusters
+}
+
+// fileValue converte um valor do YAML para a representação textual usada
+// pelas variáveis de ambiente.
+func fileValue(s setting, v any) (string, bool) {
+	switch s.Kind {
+	case kindInt:
+		f, ok := v.(float64)
+		if !ok || f != math.Trunc(f) {
+			return "", false
+		}
+		return strconv.FormatInt(int64(f), 10), true
+	case kindFloat:
+		f, ok := v.(float64)
+		return strconv.FormatFloat(f, 'f', -1, 64), ok
+	case kindDuration:
+		str, ok := v.(string)
+		if _, err := time.ParseDuration(str); !ok || err != nil {
+			return "", false
+		}
+		return str, true
+	case kindBool:
+		b, ok := v.(bool)
+		return strconv.FormatBool(b), ok
+	case kindList:
+		return fileList(v)
+	case kindMap:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		entries := make([]string, 0, len(m))
+		for k, item := range m {
+			if f, isNum := item.(float64); isNum {
+				item = strconv.FormatFloat(f, 'f', -1, 64)
+			}
+			list, ok := fileList(item)
+			if !ok {
+				return "", false
+			}
+			entries = append(entries, k+"="+list)
+		}
+		sort.Strings(entries)
+		return strings.Join(entries, ";"), true
+	}
+	str, ok := v.(string)
+	return str, ok
+}
+
+// fileList aceita uma lista YAML ou uma string separada por vírgulas.
+func fileList(v any) (string, bool) {
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case []any:
+		items := make([]string, 0, len(t))
+		for _, item := range t {
+			str, ok := item.(string)
+			if !ok {
+				return "", false
+			}
+			items = append(items, str)
+		}
+		return strings.Join(items, ","), true
+	}
+	return "", false
+}
+
+// printValue converte o valor textual de volta ao tipo YAML da opção.
+func printValue(s setting, v string) any {
+	switch s.Kind {
+	case kindInt:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	case kindFloat:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case kindBool:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	case kindList:
+		return splitList(v, ",")
+	case kindMap:
+		if m, err := parseMap(s.Env, v); err == nil {
+			return m
+		}
+	}
+	return v
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Recursos cujos labels e annotations podem ser expostos.
+const (
+	ResourceNamespaces   = "namespaces"
+	ResourceNodes        = "nodes"
+	ResourceDeployments  = "deployments"
+	ResourceStatefulSets = "statefulsets"
+	ResourceDaemonSets   = "daemonsets"
+	ResourceJobs         = "jobs"
+)
+
+// MetadataAllowlistConfig lista, por recurso, os labels e annotations
+// Kubernetes expostos nas séries *_labels/*_annotations e no JSON ("*" expõe
+// todos). Recursos ausentes não expõem nada.
+type MetadataAllowlistConfig struct {
+	Labels      map[string][]string
+	Annotations map[string][]string
+}
+
+func loadMetadataAllowlist(l *loader) MetadataAllowlistConfig {
+	var c MetadataAllowlistConfig
+	for _, opt := range []struct {
+		key string
+		dst *map[string][]string
+	}{{"METRICS_LABELS_ALLOWLIST", &c.Labels}, {"METRICS_ANNOTATIONS_ALLOWLIST", &c.Annotations}} {
+		m, err := parseMap(opt.key, l.get(opt.key))
+		if err != nil {
+			l.fail(err)
+		}
+		if len(m) > 0 {
+			*opt.dst = m
+		}
+	}
+	return c
+}
+
+// Includes indica se algum label ou annotation do recurso é exposto.
+func (c MetadataAllowlistConfig) Includes(resource string) bool {
+	return len(c.Labels[resource]) > 0 || len(c.Annotations[resource]) > 0
+}
+
+func (c MetadataAllowlistConfig) validate() []error {
+	known := []string{ResourceNamespaces, ResourceNodes, ResourceDeployments, ResourceStatefulSets, ResourceDaemonSets, ResourceJobs}
+	var errs []error
+	for _, opt := range []struct {
+		key string
+		m   map[string][]string
+	}{{"METRICS_LABELS_ALLOWLIST", c.Labels}, {"METRICS_ANNOTATIONS_ALLOWLIST", c.Annotations}} {
+		for _, resource := range sortedMapKeys(opt.m) {
+			if !slices.Contains(known, resource) {
+				errs = append(errs, &ConfigError{opt.key + " com recurso desconhecido: " + resource + " (use " + strings.Join(known, ", ") + ")"})
+			}
+		}
+	}
+	return errs
+}
+
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
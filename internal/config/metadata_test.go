@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMetadataAllowlist(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		expected    MetadataAllowlistConfig
+		expectError string
+	}{
+		{
+			name: "should load allowlists",
+			env: map[string]string{
+				"METRICS_LABELS_ALLOWLIST":      "namespaces=team,cost-center;nodes=*",
+				"METRICS_ANNOTATIONS_ALLOWLIST": "deployments=owner",
+			},
+			expected: MetadataAllowlistConfig{
+				Labels:      map[string][]string{ResourceNamespaces: {"team", "cost-center"}, ResourceNodes: {"*"}},
+				Annotations: map[string][]string{ResourceDeployments: {"owner"}},
+			},
+		},
+		{
+			name: "should load workload kinds",
+			env: map[string]string{
+				"METRICS_LABELS_ALLOWLIST":      "statefulsets=team;daemonsets=*",
+				"METRICS_ANNOTATIONS_ALLOWLIST": "jobs=owner",
+			},
+			expected: MetadataAllowlistConfig{
+				Labels:      map[string][]string{ResourceStatefulSets: {"team"}, ResourceDaemonSets: {"*"}},
+				Annotations: map[string][]string{ResourceJobs: {"owner"}},
+			},
+		},
+		{
+			name:        "should reject unknown resource",
+			env:         map[string]string{"METRICS_LABELS_ALLOWLIST": "pods=app"},
+			expectError: "METRICS_LABELS_ALLOWLIST com recurso desconhecido: pods",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			clearEnv(t)
+			t.Setenv("EXPECTED_AUTH_TOKEN", "secret")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// Act
+			cfg, err := Load(nil)
+
+			// Assert
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.MetadataAllowlist)
+		})
+	}
+}
+
+func TestMetadataAllowlistIncludes(t *testing.T) {
+	// Arrange
+	c := MetadataAllowlistConfig{
+		Labels:      map[string][]string{ResourceStatefulSets: {"team"}},
+		Annotations: map[string][]string{ResourceJobs: {"owner"}},
+	}
+
+	// Act & Assert
+	assert.True(t, c.Includes(ResourceStatefulSets))
+	assert.True(t, c.Includes(ResourceJobs))
+	assert.False(t, c.Includes(ResourceDaemonSets))
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OIDCConfig configura a autenticação por JWT emitido por um provedor OIDC.
+type OIDCConfig struct {
+	Issuer      string
+	Audience    string
+	JWKSURL     string
+	JWKSFile    string
+	GroupsClaim string
+	// GroupNamespaces mapeia grupo -> namespaces permitidos ("*" libera todos).
+	// Vazio significa sem restrição por namespace.
+	GroupNamespaces map[string][]string
+	// Scopes concedidos a qualquer JWT válido.
+	Scopes []string
+}
+
+// Enabled indica se a autenticação OIDC foi configurada.
+func (o OIDCConfig) Enabled() bool { return o.Issuer != "" }
+
+func loadOIDC(l *loader) OIDCConfig {
+	o := OIDCConfig{
+		Issuer:      l.get("OIDC_ISSUER"),
+		Audience:    l.get("OIDC_AUDIENCE"),
+		JWKSURL:     l.get("OIDC_JWKS_URL"),
+		JWKSFile:    l.get("OIDC_JWKS_FILE"),
+		GroupsClaim: l.get("OIDC_GROUPS_CLAIM"),
+		Scopes:      l.list("OIDC_SCOPES"),
+	}
+	m, err := parseMap("OIDC_GROUP_NAMESPACES", l.get("OIDC_GROUP_NAMESPACES"))
+	if err != nil {
+		l.fail(err)
+	}
+	o.GroupNamespaces = m
+	return o
+}
+
+// validate verifica a configuração OIDC quando habilitada.
+func (o OIDCConfig) validate() []error {
+	if !o.Enabled() {
+		return nil
+	}
+	var errs []error
+	if o.Audience == "" {
+		errs = append(errs, &ConfigError{"OIDC_AUDIENCE é obrigatório quando OIDC_ISSUER está definido"})
+	}
+	if o.GroupsClaim == "" {
+		errs = append(errs, &ConfigError{"OIDC_GROUPS_CLAIM não pode ser vazio"})
+	}
+	if len(o.Scopes) == 0 {
+		errs = append(errs, &ConfigError{"OIDC_SCOPES não pode ser vazio"})
+	}
+	for _, s := range o.Scopes {
+		if !knownScopes[s] {
+			errs = append(errs, &ConfigError{"OIDC_SCOPES com escopo desconhecido: " + s})
+		}
+	}
+	return errs
+}
+
+// parseMap interpreta opções no formato "chave=v1,v2;outra=v3".
+func parseMap(key, v string) (map[string][]string, error) {
+	m := map[string][]string{}
+	for _, entry := range splitList(v, ";") {
+		k, items, ok := strings.Cut(entry, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, &ConfigError{fmt.Sprintf("%s inválido: %q", key, entry)}
+		}
+		m[k] = append(m[k], splitList(items, ",")...)
+	}
+	return m, nil
+}
+
+func splitList(v, sep string) []string {
+	var out []string
+	for _, p := range strings.Split(v, sep) {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,21 @@
+package config
+
+// RateLimitConfig define limites de requisição por cliente e de coletas simultâneas.
+// Taxas em requisições por segundo; zero desabilita o limite correspondente.
+type RateLimitConfig struct {
+	IdentityRPS              float64
+	IdentityBurst            int
+	IPRPS                    float64
+	IPBurst                  int
+	MaxConcurrentCollections int
+}
+
+func loadRateLimit(l *loader) RateLimitConfig {
+	return RateLimitConfig{
+		IdentityRPS:              l.float("RATE_LIMIT_IDENTITY_RPS"),
+		IdentityBurst:            l.int("RATE_LIMIT_IDENTITY_BURST"),
+		IPRPS:                    l.float("RATE_LIMIT_IP_RPS"),
+		IPBurst:                  l.int("RATE_LIMIT_IP_BURST"),
+		MaxConcurrentCollections: l.int("MAX_CONCURRENT_COLLECTIONS"),
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reloader relê a configuração (arquivo, ambiente e flags originais) em
+// SIGHUP ou quando o arquivo muda, e aplica as opções seguras em tempo de
+// execução. Alterações nas demais opções são apenas registradas no log.
+type Reloader struct {
+	log *slog.Logger
+
+	// Success indica se o último reload foi aplicado (1) ou rejeitado (0).
+	Success prometheus.Gauge
+	// LastSuccess horário do último reload aplicado, em segundos Unix.
+	LastSuccess prometheus.Gauge
+
+	mu      sync.Mutex
+	current *Config
+	hooks   []reloadHook
+	safe    map[string]bool
+	modTime time.Time
+	size    int64
+}
+
+type reloadHook struct {
+	keys []string
+	fn   func(*Config)
+}
+
+// NewReloader cria Reloader para cfg e registra suas métricas. Nível de log e
+// tokens (EXPECTED_AUTH_TOKEN, AUTH_TOKENS_FILE) já são aplicados.
+func NewReloader(cfg *Config, reg prometheus.Registerer, logger *slog.Logger) *Reloader {
+	r := &Reloader{
+		log:     logger,
+		current: cfg,
+		safe:    map[string]bool{},
+		Success: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "k8s_metrics_api_config_reload_success",
+			Help: "1 se o último reload de configuração foi aplicado, 0 se falhou",
+		}),
+		LastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "k8s_metrics_api_config_last_reload_success_timestamp_seconds",
+			Help: "Horário do último reload de configuração aplicado",
+		}),
+	}
+	r.Success.Set(1)
+	r.LastSuccess.SetToCurrentTime()
+	_ = reg.Register(r.Success) // ignora AlreadyRegistered
+	_ = reg.Register(r.LastSuccess)
+	r.modTime, r.size = fileStamp(cfg.ConfigFile)
+
+	r.OnReload(func(c *Config) {
+		_ = cfg.Level.UnmarshalText([]byte(c.LogLevel))
+	}, "LOG_LEVEL")
+	r.OnReload(func(c *Config) {
+		cfg.Tokens.adopt(c.Tokens)
+	}, "EXPECTED_AUTH_TOKEN", "AUTH_TOKENS_FILE")
+	return r
+}
+
+// OnReload registra fn para ser chamada com a nova configuração quando alguma
+// das opções keys mudar. As opções registradas passam a ser aplicadas sem
+// reinício.
+func (r *Reloader) OnReload(fn func(*Config), keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, reloadHook{keys: keys, fn: fn})
+	for _, k := range keys {
+		r.safe[k] = true
+	}
+}
+
+// Current retorna a última configuração aplicada.
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload relê a configuração e aplica as opções seguras que mudaram. Se a nova
+// configuração for inválida, a atual é mantida.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := Load(r.current.args)
+	if err != nil {
+		r.Success.Set(0)
+		r.log.Error("Reload de configuração rejeitado", "error", err)
+		return err
+	}
+
+	changed := map[string]bool{}
+	for _, s := range settings {
+		old, cur := r.current.resolved[s.Env], next.resolved[s.Env]
+		if old == cur {
+			continue
+		}
+		changed[s.Env] = true
+		if s.Secret {
+			old, cur = redacted, redacted
+		}
+		if r.safe[s.Env] {
+			r.log.Info("Configuração alterada", "key", s.Env, "old", old, "new", cur)
+		} else {
+			r.log.Warn("Alteração de configuração requer reinício", "key", s.Env, "old", old, "new", cur)
+		}
+	}
+	if !reflect.DeepEqual(r.current.Clusters, next.Clusters) {
+		r.log.Warn("Alteração de configuração requer reinício", "key", "clusters")
+	}
+
+	for _, h := range r.hooks {
+		for _, k := range h.keys {
+			if changed[k] {
+				h.fn(next)
+				break
+			}
+		}
+	}
+
+	// Recursos vivos continuam sendo os da configuração original.
+	next.Tokens, next.Logger, next.Level = r.current.Tokens, r.current.Logger, r.current.Level
+	r.current = next
+	r.Success.Set(1)
+	r.LastSuccess.SetToCurrentTime()
+	r.log.Info("Configuração recarregada", "changes", len(changed))
+	return nil
+}
+
+// Run recarrega a configuração a cada SIGHUP e, com arquivo de configuração e
+// WatchInterval definidos, sempre que o arquivo mudar.
+func (r *Reloader) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	cfg := r.Current()
+	if cfg.ConfigFile != "" && cfg.WatchInterval > 0 {
+		ticker := time.NewTicker(cfg.WatchInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.log.Info("SIGHUP recebido, recarregando configuração")
+			_ = r.Reload()
+		case <-tick:
+			if r.fileChanged() {
+				_ = r.Reload()
+			}
+		}
+	}
+}
+
+func (r *Reloader) fileChanged() bool {
+	modTime, size := fileStamp(r.Current().ConfigFile)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if modTime.Equal(r.modTime) && size == r.size {
+		return false
+	}
+	r.modTime, r.size = modTime, size
+	return true
+}
+
+func fileStamp(path string) (time.Time, int64) {
+	if path == "" {
+		return time.Time{}, 0
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReloader(t *testing.T, content string) (*Reloader, *Config, string) {
+	t.Helper()
+	clearEnv(t)
+	path := writeConfigFile(t, content)
+	cfg, err := Load([]string{"--config", path})
+	require.NoError(t, err)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	return NewReloader(cfg, prometheus.NewRegistry(), logger), cfg, path
+}
+
+// rewrite grava o arquivo garantindo que a mudança seja percebida pelo mtime.
+func rewrite(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, future, future))
+}
+
+func TestReloaderAppliesSafeChanges(t *testing.T) {
+	// Arrange
+	r, cfg, path := newTestReloader(t, "auth:\n  token: old-secret\nlog:\n  level: info\n")
+	var hookCalls int
+	r.OnReload(func(*Config) { hookCalls++ }, "RATE_LIMIT_IP_RPS")
+	rewrite(t, path, "auth:\n  token: new-secret\nlog:\n  level: debug\nserver:\n  port: 9090\n")
+
+	// Act
+	changed := r.fileChanged()
+	err := r.Reload()
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, slog.LevelDebug, cfg.Level.Level())
+	_, oldOK := cfg.Tokens.Lookup("old-secret")
+	_, newOK := cfg.Tokens.Lookup("new-secret")
+	assert.False(t, oldOK)
+	assert.True(t, newOK)
+	assert.Zero(t, hookCalls)
+	assert.Equal(t, "9090", r.Current().Port)
+	assert.Same(t, cfg.Tokens, r.Current().Tokens)
+	assert.Equal(t, 1.0, testutil.ToFloat64(r.Success))
+}
+
+func TestReloaderRunsHooksForChangedKeys(t *testing.T) {
+	// Arrange
+	r, _, path := newTestReloader(t, "auth:\n  token: secret\n")
+	var got float64
+	r.OnReload(func(c *Config) { got = c.RateLimit.IPRPS }, "RATE_LIMIT_IP_RPS")
+	rewrite(t, path, "auth:\n  token: secret\nrateLimit:\n  ipRPS: 42\n")
+
+	// Act
+	err := r.Reload()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, got)
+}
+
+func TestReloaderKeepsConfigOnError(t *testing.T) {
+	// Arrange
+	r, cfg, path := newTestReloader(t, "auth:\n  token: secret\n")
+	rewrite(t, path, "auth:\n  token: secret\nlog:\n  level: loud\n")
+
+	// Act
+	err := r.Reload()
+
+	// Assert
+	assert.Error(t, err)
+	assert.Same(t, cfg, r.Current())
+	assert.Equal(t, slog.LevelInfo, cfg.Level.Level())
+	_, ok := cfg.Tokens.Lookup("secret")
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, testutil.ToFloat64(r.Success))
+}
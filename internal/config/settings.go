@@ -0,0 +1,140 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// kind define como o valor de uma opção é lido do arquivo de configuração e
+// exibido por --print-config.
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindFloat
+	kindDuration
+	kindList
+	kindMap
+	kindBool
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindInt:
+		return "inteiro"
+	case kindFloat:
+		return "número"
+	case kindDuration:
+		return `duração (ex.: "30s")`
+	case kindList:
+		return "lista"
+	case kindMap:
+		return "mapa de listas"
+	case kindBool:
+		return "booleano"
+	}
+	return "texto"
+}
+
+// setting descreve uma opção de configuração. Env é a chave canônica (usada
+// nas mensagens de erro), Path o caminho no arquivo YAML e a flag de linha de
+// comando é derivada de Env ("KUBE_API_QPS" -> --kube-api-qps).
+type setting struct {
+	Env     string
+	Path    string
+	Kind    kind
+	Default string
+	Help    string
+	Secret  bool
+}
+
+func (s setting) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(s.Env), "_", "-")
+}
+
+var settings = []setting{
+	{Env: "PORT", Path: "server.port", Kind: kindInt, Default: "8080", Help: "porta HTTP"},
+	{Env: "LOG_LEVEL", Path: "log.level", Default: "info", Help: "nível de log (debug, info, warn, error)"},
+	{Env: "LOG_FORMAT", Path: "log.format", Default: "json", Help: "formato de log (json, text)"},
+	{Env: "CONFIG_WATCH_INTERVAL", Path: "reload.watchInterval", Kind: kindDuration, Default: "10s", Help: "intervalo de verificação do arquivo de configuração (0 desabilita)"},
+	{Env: "TRUSTED_PROXIES", Path: "server.trustedProxies", Kind: kindList, Help: "CIDRs de proxies confiáveis para X-Forwarded-For"},
+
+	{Env: "EXPECTED_AUTH_TOKEN", Path: "auth.token", Help: "token legado com acesso total", Secret: true},
+	{Env: "AUTH_TOKENS_FILE", Path: "auth.tokensFile", Help: "arquivo de tokens nomeados"},
+	{Env: "AUTH_TOKENS_RELOAD_INTERVAL", Path: "auth.tokensReloadInterval", Kind: kindDuration, Default: DefaultTokensReloadInterval.String(), Help: "intervalo de verificação do arquivo de tokens"},
+	{Env: "AUTH_SCHEMES", Path: "auth.schemes", Kind: kindList, Default: AuthSchemeBearer, Help: "esquemas HTTP aceitos (bearer, basic)"},
+	{Env: "AUTH_BASIC_USERNAME", Path: "auth.basicUsername", Help: "usuário exigido no Basic auth"},
+
+	{Env: "OIDC_ISSUER", Path: "oidc.issuer", Help: "issuer OIDC; habilita JWT"},
+	{Env: "OIDC_AUDIENCE", Path: "oidc.audience", Help: "audience esperado no JWT"},
+	{Env: "OIDC_JWKS_URL", Path: "oidc.jwksURL", Help: "URL do JWKS (padrão: discovery)"},
+	{Env: "OIDC_JWKS_FILE", Path: "oidc.jwksFile", Help: "arquivo JWKS local"},
+	{Env: "OIDC_GROUPS_CLAIM", Path: "oidc.groupsClaim", Default: "groups", Help: "claim com os grupos"},
+	{Env: "OIDC_GROUP_NAMESPACES", Path: "oidc.groupNamespaces", Kind: kindMap, Help: `grupo -> namespaces ("grp=ns1,ns2;sre=*")`},
+	{Env: "OIDC_SCOPES", Path: "oidc.scopes", Kind: kindList, Default: ScopeMetricsRead, Help: "escopos concedidos a JWTs válidos"},
+
+	{Env: "RATE_LIMIT_IDENTITY_RPS", Path: "rateLimit.identityRPS", Kind: kindFloat, Default: "1", Help: "requisições/s por identidade"},
+	{Env: "RATE_LIMIT_IDENTITY_BURST", Path: "rateLimit.identityBurst", Kind: kindInt, Default: "5", Help: "burst por identidade"},
+	{Env: "RATE_LIMIT_IP_RPS", Path: "rateLimit.ipRPS", Kind: kindFloat, Default: "5", Help: "requisições/s por IP"},
+	{Env: "RATE_LIMIT_IP_BURST", Path: "rateLimit.ipBurst", Kind: kindInt, Default: "10", Help: "burst por IP"},
+	{Env: "MAX_CONCURRENT_COLLECTIONS", Path: "rateLimit.maxConcurrentCollections", Kind: kindInt, Default: "4", Help: "coletas simultâneas"},
+
+	{Env: "AUDIT_LOG", Path: "audit.sink", Help: "destino do log de auditoria (stdout, stderr ou arquivo)"},
+	{Env: "AUDIT_LOG_MAX_SIZE_MB", Path: "audit.maxSizeMB", Kind: kindInt, Default: "100", Help: "tamanho máximo do arquivo de auditoria"},
+	{Env: "AUDIT_LOG_MAX_BACKUPS", Path: "audit.maxBackups", Kind: kindInt, Default: "5", Help: "arquivos de auditoria rotacionados mantidos"},
+
+	{Env: "NAMESPACE_INCLUDE", Path: "collection.namespaces.include", Kind: kindList, Help: "namespaces coletados (vazio: todos)"},
+	{Env: "NAMESPACE_EXCLUDE", Path: "collection.namespaces.exclude", Kind: kindList, Help: "namespaces ignorados"},
+	{Env: "NAMESPACE_INCLUDE_REGEX", Path: "collection.namespaces.includeRegex", Help: "regex de namespaces coletados"},
+	{Env: "NAMESPACE_EXCLUDE_REGEX", Path: "collection.namespaces.excludeRegex", Help: "regex de namespaces ignorados"},
+	{Env: "NAMESPACE_LABEL_SELECTOR", Path: "collection.namespaces.labelSelector", Help: "label selector de namespaces coletados"},
+	{Env: "POD_LABEL_SELECTOR", Path: "collection.pods.labelSelector", Help: "label selector de pods coletados"},
+	{Env: "COLLECTION_WORKERS", Path: "collection.workers", Kind: kindInt, Default: strconv.Itoa(DefaultCollectionWorkers), Help: "listagens simultâneas por coleta de cluster"},
+	{Env: "COLLECTION_TIMEOUT", Path: "collection.timeout", Kind: kindDuration, Default: DefaultCollectionTimeout.String(), Help: "prazo de cada coleta de cluster"},
+	{Env: "COLLECTORS_ENABLED", Path: "collection.collectors.enabled", Kind: kindList, Help: "collectors executados (vazio: todos)"},
+	{Env: "COLLECTORS_DISABLED", Path: "collection.collectors.disabled", Kind: kindList, Help: "collectors desabilitados"},
+
+	{Env: "METRICS_DROP_LABELS", Path: "metrics.dropLabels", Kind: kindMap, Help: `família -> labels descartados ("k8s_container_restarts_total=pod,container")`},
+	{Env: "METRICS_MAX_SERIES", Path: "metrics.maxSeries", Kind: kindMap, Help: `família -> limite de séries ("k8s_pod_status_phase=500")`},
+	{Env: "METRICS_LABELS_ALLOWLIST", Path: "metrics.labelsAllowlist", Kind: kindMap, Help: `recurso -> labels expostos ("namespaces=team,cost-center;nodes=*")`},
+	{Env: "METRICS_ANNOTATIONS_ALLOWLIST", Path: "metrics.annotationsAllowlist", Kind: kindMap, Help: "recurso -> annotations expostas"},
+	{Env: "IMAGE_ALLOWED_REGISTRIES", Path: "metrics.images.allowedRegistries", Kind: kindList, Help: "registries ou prefixos de repositório permitidos (vazio: todos)"},
+	{Env: "IMAGE_DISALLOWED_TAGS", Path: "metrics.images.disallowedTags", Kind: kindList, Default: "latest", Help: "tags de imagem proibidas"},
+	{Env: "COMPLIANCE_RULES_ENABLED", Path: "metrics.compliance.rules.enabled", Kind: kindList, Help: "regras de conformidade avaliadas (vazio: todas)"},
+	{Env: "COMPLIANCE_RULES_DISABLED", Path: "metrics.compliance.rules.disabled", Kind: kindList, Help: "regras de conformidade desabilitadas"},
+	{Env: "TLS_EXPIRY_WINDOW", Path: "metrics.certificates.expiryWindow", Kind: kindDuration, Default: DefaultCertificateExpiryWindow.String(), Help: "janela de certificados a expirar listados em /metrics/certificates"},
+	{Env: "TLS_CERT_MANAGER", Path: "metrics.certificates.certManager", Kind: kindBool, Default: "false", Help: "coleta Certificates do cert-manager"},
+	{Env: "CUSTOM_RESOURCES_FILE", Path: "metrics.customResourcesFile", Help: "arquivo com as métricas de recursos customizados"},
+	{Env: "METRICS_DEFAULT_MAX_SERIES", Path: "metrics.defaultMaxSeries", Kind: kindInt, Default: "0", Help: "limite de séries por família; 0 desabilita"},
+
+	{Env: "KUBECONFIG", Path: "kubernetes.kubeconfig", Help: "kubeconfig(s) separados por ':'"},
+	{Env: "KUBE_CONTEXT", Path: "kubernetes.context", Help: "contexto do kubeconfig"},
+	{Env: "KUBE_API_QPS", Path: "kubernetes.qps", Kind: kindFloat, Default: strconv.Itoa(DefaultKubeQPS), Help: "QPS contra o API server"},
+	{Env: "KUBE_API_BURST", Path: "kubernetes.burst", Kind: kindInt, Default: strconv.Itoa(DefaultKubeBurst), Help: "burst contra o API server"},
+	{Env: "KUBE_API_TIMEOUT", Path: "kubernetes.timeout", Kind: kindDuration, Default: DefaultKubeTimeout.String(), Help: "timeout das requisições ao API server"},
+	{Env: "KUBE_LIST_PAGE_SIZE", Path: "kubernetes.listPageSize", Kind: kindInt, Default: strconv.Itoa(DefaultKubeListPageSize), Help: "objetos por página nas listagens; 0 desabilita a paginação"},
+	{Env: "KUBE_USER_AGENT", Path: "kubernetes.userAgent", Default: DefaultKubeUserAgent, Help: "User-Agent enviado ao API server"},
+	{Env: "CLUSTERS_FILE", Path: "kubernetes.clustersFile", Help: "arquivo com a lista de clusters"},
+	{Env: "CLUSTER_NAME", Path: "kubernetes.clusterName", Default: DefaultClusterName, Help: "nome do cluster único"},
+}
+
+var (
+	settingByEnv  = map[string]setting{}
+	settingByPath = map[string]setting{}
+	settingByFlag = map[string]setting{}
+	// sections guarda os prefixos válidos do arquivo ("auth", "oidc"...).
+	sections = map[string]bool{}
+)
+
+func init() {
+	for _, s := range settings {
+		settingByEnv[s.Env] = s
+		settingByPath[s.Path] = s
+		settingByFlag[s.flagName()] = s
+		parts := strings.Split(s.Path, ".")
+		for i := 1; i < len(parts); i++ {
+			sections[strings.Join(parts[:i], ".")] = true
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Escopos reconhecidos pelos tokens de API.
+const (
+	ScopeMetricsRead      = "metrics:read"
+	ScopePrometheusScrape = "prometheus:scrape"
+	ScopeAdmin            = "admin"
+)
+
+var knownScopes = map[string]bool{
+	ScopeMetricsRead:      true,
+	ScopePrometheusScrape: true,
+	ScopeAdmin:            true,
+}
+
+// Token representa um token de API nomeado e seus escopos.
+type Token struct {
+	Name   string   `json:"name"`
+	Value  string   `json:"token"`
+	Scopes []string `json:"scopes"`
+}
+
+type tokensFile struct {
+	Tokens []Token `json:"tokens"`
+}
+
+// LoadTokensFile lê um arquivo YAML ou JSON com a lista de tokens nomeados.
+func LoadTokensFile(path string) ([]Token, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f tokensFile
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("arquivo de tokens %s inválido: %w", path, err)
+	}
+	if err := validateTokens(f.Tokens); err != nil {
+		return nil, fmt.Errorf("arquivo de tokens %s inválido: %w", path, err)
+	}
+	return f.Tokens, nil
+}
+
+func validateTokens(tokens []Token) error {
+	names := map[string]bool{}
+	values := map[string]bool{}
+	for i, t := range tokens {
+		if t.Name == "" {
+			return fmt.Errorf("token #%d sem nome", i)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("nome de token duplicado: %s", t.Name)
+		}
+		names[t.Name] = true
+		if t.Value == "" {
+			return fmt.Errorf("token %s sem valor", t.Name)
+		}
+		if values[t.Value] {
+			return fmt.Errorf("token %s reutiliza o valor de outro token", t.Name)
+		}
+		values[t.Value] = true
+		for _, s := range t.Scopes {
+			if !knownScopes[s] {
+				return fmt.Errorf("token %s com escopo desconhecido: %s", t.Name, s)
+			}
+		}
+	}
+	return nil
+}
+
+// TokenStore mantém o conjunto de tokens ativo e permite recarregá-lo do arquivo.
+type TokenStore struct {
+	path   string
+	static []Token
+
+	mu      sync.RWMutex
+	tokens  []hashedToken
+	modTime time.Time
+	size    int64
+}
+
+type hashedToken struct {
+	Token
+	sum [sha256.Size]byte
+}
+
+// NewTokenStore cria o store a partir do arquivo (opcional) e de tokens fixos.
+func NewTokenStore(path string, static ...Token) (*TokenStore, error) {
+	s := &TokenStore{path: path, static: static}
+	if path == "" {
+		s.set(nil)
+		return s, nil
+	}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Lookup procura o token secreto informado. A comparação é feita em tempo
+// constante contra todos os tokens, sem interromper no primeiro acerto.
+func (s *TokenStore) Lookup(secret string) (Token, bool) {
+	if secret == "" {
+		return Token{}, false
+	}
+	sum := sha256.Sum256([]byte(secret))
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var found Token
+	ok := false
+	for _, t := range s.tokens {
+		if subtle.ConstantTimeCompare(sum[:], t.sum[:]) == 1 {
+			found, ok = t.Token, true
+		}
+	}
+	return found, ok
+}
+
+// Len retorna a quantidade de tokens ativos.
+func (s *TokenStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.tokens)
+}
+
+// Path retorna o arquivo de tokens atual.
+func (s *TokenStore) Path() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.path
+}
+
+// Reload relê o arquivo de tokens se ele mudou desde a última leitura.
+// Retorna true quando o conjunto ativo foi substituído.
+func (s *TokenStore) Reload() (bool, error) {
+	s.mu.RLock()
+	path, static, modTime, size := s.path, s.static, s.modTime, s.size
+	s.mu.RUnlock()
+	if path == "" {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(modTime) && info.Size() == size {
+		return false, nil
+	}
+	tokens, err := LoadTokensFile(path)
+	if err != nil {
+		return false, err
+	}
+	if err := validateTokens(append(append([]Token{}, static...), tokens...)); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path != path {
+		// Substituído por adopt durante a leitura.
+		return false, nil
+	}
+	s.tokens = hashTokens(static, tokens)
+	s.modTime, s.size = info.ModTime(), info.Size()
+	return true, nil
+}
+
+func (s *TokenStore) set(fromFile []Token) {
+	all := hashTokens(s.static, fromFile)
+	s.mu.Lock()
+	s.tokens = all
+	s.mu.Unlock()
+}
+
+func hashTokens(static, fromFile []Token) []hashedToken {
+	all := make([]hashedToken, 0, len(static)+len(fromFile))
+	for _, t := range append(append([]Token{}, static...), fromFile...) {
+		all = append(all, hashedToken{Token: t, sum: sha256.Sum256([]byte(t.Value))})
+	}
+	return all
+}
+
+// adopt substitui o conteúdo do store pelo de o, já carregado e validado.
+func (s *TokenStore) adopt(o *TokenStore) {
+	o.mu.RLock()
+	path, static, tokens, modTime, size := o.path, o.static, o.tokens, o.modTime, o.size
+	o.mu.RUnlock()
+	s.mu.Lock()
+	s.path, s.static, s.tokens, s.modTime, s.size = path, static, tokens, modTime, size
+	s.mu.Unlock()
+}
+
+// Watch verifica periodicamente o arquivo de tokens e recarrega quando ele muda.
+// Em caso de erro o conjunto anterior é mantido. O arquivo pode ser definido
+// depois, por um reload de configuração.
+func (s *TokenStore) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := s.Reload()
+			if err != nil {
+				logger.Error("Erro ao recarregar arquivo de tokens", "path", s.Path(), "error", err)
+				continue
+			}
+			if changed {
+				logger.Info("Arquivo de tokens recarregado", "path", s.Path(), "tokens", s.Len())
+			}
+		}
+	}
+}
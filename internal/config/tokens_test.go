@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tokensYAML = `
+tokens:
+  - name: grafana
+    token: grafana-secret
+    scopes: [metrics:read]
+  - name: prometheus
+    token: prom-secret
+    scopes: [prometheus:scrape]
+`
+
+func writeTokensFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "tokens.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadTokensFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedLen int
+		expectError bool
+	}{
+		{
+			name:        "should load yaml tokens",
+			content:     tokensYAML,
+			expectedLen: 2,
+		},
+		{
+			name:        "should load json tokens",
+			content:     `{"tokens":[{"name":"ci","token":"ci-secret","scopes":["admin"]}]}`,
+			expectedLen: 1,
+		},
+		{
+			name:        "should reject unknown scope",
+			content:     `{"tokens":[{"name":"ci","token":"ci-secret","scopes":["write"]}]}`,
+			expectError: true,
+		},
+		{
+			name:        "should reject duplicated names",
+			content:     `{"tokens":[{"name":"ci","token":"a"},{"name":"ci","token":"b"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "should reject token without value",
+			content:     `{"tokens":[{"name":"ci"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "should reject unknown fields",
+			content:     `{"tokens":[{"name":"ci","token":"a","scope":["admin"]}]}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := writeTokensFile(t, t.TempDir(), tt.content)
+
+			// Act
+			tokens, err := LoadTokensFile(path)
+
+			// Assert
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, tokens, tt.expectedLen)
+		})
+	}
+}
+
+func TestTokenStoreLookup(t *testing.T) {
+	// Arrange
+	path := writeTokensFile(t, t.TempDir(), tokensYAML)
+	store, err := NewTokenStore(path, Token{Name: "default", Value: "legacy", Scopes: []string{ScopeAdmin}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		secret       string
+		expectedName string
+		expectedOK   bool
+	}{
+		{name: "should find token from file", secret: "grafana-secret", expectedName: "grafana", expectedOK: true},
+		{name: "should find static token", secret: "legacy", expectedName: "default", expectedOK: true},
+		{name: "should reject unknown token", secret: "nope", expectedOK: false},
+		{name: "should reject empty token", secret: "", expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			tok, ok := store.Lookup(tt.secret)
+
+			// Assert
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedName, tok.Name)
+		})
+	}
+}
+
+func TestTokenStoreReload(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	path := writeTokensFile(t, dir, tokensYAML)
+	store, err := NewTokenStore(path)
+	require.NoError(t, err)
+
+	// Act - rotaciona o token do grafana
+	rotated := `{"tokens":[{"name":"grafana","token":"grafana-new","scopes":["metrics:read"]}]}`
+	require.NoError(t, os.WriteFile(path, []byte(rotated), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+	changed, err := store.Reload()
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, changed)
+	_, ok := store.Lookup("grafana-secret")
+	assert.False(t, ok)
+	tok, ok := store.Lookup("grafana-new")
+	assert.True(t, ok)
+	assert.Equal(t, "grafana", tok.Name)
+
+	// Act - arquivo inválido mantém o conjunto anterior
+	require.NoError(t, os.WriteFile(path, []byte("tokens: [{name: x}]"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now().Add(2*time.Minute), time.Now().Add(2*time.Minute)))
+	_, err = store.Reload()
+
+	// Assert
+	assert.Error(t, err)
+	_, ok = store.Lookup("grafana-new")
+	assert.True(t, ok)
+}
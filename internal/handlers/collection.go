@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s-metrics-api/internal/metrics"
+)
+
+// Section estado da coleta de um tipo de recurso. Stale indica que a última
+// listagem falhou e os dados são os da listagem de CollectedAt (zero se nunca
+// houve sucesso).
+type Section struct {
+	Stale       bool      `json:"stale"`
+	CollectedAt time.Time `json:"collectedAt,omitzero"`
+	// DurationSeconds duração da última tentativa de listagem.
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// CollectionError falha ao listar um tipo de recurso.
+type CollectionError struct {
+	Cluster  string `json:"cluster,omitempty"`
+	Resource string `json:"resource"`
+	Error    string `json:"error"`
+}
+
+// listCache guarda o último resultado bem-sucedido de cada collector de um
+// cluster, reaplicado quando a listagem seguinte falha.
+type listCache struct {
+	mu      sync.Mutex
+	results map[string]cachedResult
+	// apply serializa a aplicação dos resultados nas métricas do cluster:
+	// cada Apply zera e soma séries, e duas coletas simultâneas intercaladas
+	// somariam em dobro.
+	apply sync.Mutex
+}
+
+type cachedResult struct {
+	result metrics.Result
+	at     time.Time
+}
+
+func newListCache() *listCache { return &listCache{results: map[string]cachedResult{}} }
+
+// collect executa collectors no cluster e atualiza suas
+// métricas Prometheus. As listagens rodam em paralelo, até Workers por vez e
+// sob o prazo de ctx; os resultados são aplicados depois, na ordem do
+// Registry, formando um único snapshot. Cada collector falha de forma
+// independente: o erro é reportado em Errors e o último resultado
+// bem-sucedido é reaplicado, com a seção marcada como stale.
+func (h *Handler) collect(ctx context.Context, cl Cluster, allowed func(string) bool, collectors []metrics.Collector) ClusterMetrics {
+	filter := h.filter.Load()
+	src := &metrics.Source{
+		Client: cl.Client, Filter: filter, Images: h.images.Load(), Compliance: h.complianceRules(),
+		Metrics: cl.Metrics.Collector, Log: h.log,
+	}
+	cache := h.caches[cl.Name]
+	out := ClusterMetrics{Cluster: cl.Name, Sections: map[string]Section{}, Errors: []CollectionError{}}
+
+	var mu sync.Mutex
+	results := make([]metrics.Result, len(collectors))
+	tasks := make([]func(), 0, len(collectors))
+	for i, col := range collectors {
+		tasks = append(tasks, func() {
+			start := time.Now()
+			res, err := col.Collect(ctx, src)
+			now := time.Now().UTC()
+			sec := Section{CollectedAt: now, DurationSeconds: now.Sub(start).Seconds()}
+
+			mu.Lock()
+			defer mu.Unlock()
+			cache.mu.Lock()
+			defer cache.mu.Unlock()
+			if err == nil {
+				cache.results[col.Name()] = cachedResult{result: res, at: now}
+				results[i] = res
+			} else {
+				out.Errors = append(out.Errors, CollectionError{Cluster: cl.Name, Resource: col.Name(), Error: err.Error()})
+				prev := cache.results[col.Name()]
+				sec.Stale, sec.CollectedAt = true, prev.at
+				results[i] = prev.result
+			}
+			out.Sections[col.Name()] = sec
+		})
+	}
+	run(h.collectionConfig().Workers, tasks...)
+
+	snap := metrics.NewSnapshot(filter, allowed)
+	cache.apply.Lock()
+	for _, res := range results {
+		if res != nil {
+			res.Apply(snap, cl.Metrics)
+		}
+	}
+	cache.apply.Unlock()
+	out.Snapshot = *snap
+
+	sort.Slice(out.Errors, func(i, j int) bool { return out.Errors[i].Resource < out.Errors[j].Resource })
+	if len(out.Errors) == 0 {
+		cl.Metrics.Collector.LastSuccess.SetToCurrentTime()
+	}
+	out.Timestamp = time.Now().UTC()
+	return out
+}
+
+// run executa tasks com no máximo workers simultâneas e aguarda todas.
+func run(workers int, tasks ...func()) {
+	sem := make(chan struct{}, max(workers, 1))
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			task()
+		}()
+	}
+	wg.Wait()
+}
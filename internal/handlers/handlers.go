@@ -5,157 +5,463 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
+	"k8s-metrics-api/internal/config"
 	"k8s-metrics-api/internal/k8s"
 	"k8s-metrics-api/internal/metrics"
+	"k8s-metrics-api/internal/middleware"
 )
 
+// Cluster associa o client de um cluster às suas métricas Prometheus.
+type Cluster struct {
+	Name    string
+	Client  *k8s.Client
+	Metrics *metrics.PrometheusMetrics
+}
+
 // Handler agrega dependências.
 type Handler struct {
-	k8s *k8s.Client
-	m   *metrics.PrometheusMetrics
-	log *slog.Logger
+	clusters   []Cluster
+	log        *slog.Logger
+	filter     atomic.Pointer[k8s.NamespaceFilter]
+	settings   atomic.Pointer[config.CollectionConfig]
+	certs      atomic.Pointer[config.CertificateConfig]
+	images     atomic.Pointer[metrics.ImagePolicy]
+	compliance atomic.Pointer[metrics.ComplianceRules]
+	collectors atomic.Pointer[[]metrics.Collector]
+	caches     map[string]*listCache
 }
 
-// New cria Handler.
+// New cria Handler para um único cluster.
 func New(k8sClient *k8s.Client, m *metrics.PrometheusMetrics, logger *slog.Logger) *Handler {
-	return &Handler{k8s: k8sClient, m: m, log: logger}
+	return NewMulti([]Cluster{{Name: k8sClient.Name, Client: k8sClient, Metrics: m}}, logger)
+}
+
+// NewMulti cria Handler para vários clusters.
+func NewMulti(clusters []Cluster, logger *slog.Logger) *Handler {
+	caches := make(map[string]*listCache, len(clusters))
+	for _, c := range clusters {
+		caches[c.Name] = newListCache()
+	}
+	return &Handler{clusters: clusters, log: logger, caches: caches}
+}
+
+// SetNamespaceFilter define quais namespaces e pods são coletados; nil coleta todos.
+func (h *Handler) SetNamespaceFilter(f *k8s.NamespaceFilter) { h.filter.Store(f) }
+
+// SetCollectionConfig define o paralelismo e o prazo das coletas.
+func (h *Handler) SetCollectionConfig(cfg config.CollectionConfig) { h.settings.Store(&cfg) }
+
+// SetCollectors define os collectors executados em cada coleta, na ordem em
+// que seus resultados são aplicados. Sem chamada, todos os de
+// metrics.DefaultRegistry são executados.
+func (h *Handler) SetCollectors(cs []metrics.Collector) { h.collectors.Store(&cs) }
+
+func (h *Handler) enabledCollectors() []metrics.Collector {
+	if cs := h.collectors.Load(); cs != nil {
+		return *cs
+	}
+	cs, _ := metrics.DefaultRegistry().Select(nil, nil)
+	return cs
+}
+
+// SetImagePolicy define a política de imagens avaliada nos pods; nil não
+// conta violações.
+func (h *Handler) SetImagePolicy(p *metrics.ImagePolicy) { h.images.Store(p) }
+
+// SetComplianceRules define as regras de conformidade avaliadas nos pods e
+// deployments. Sem chamada, todas são avaliadas.
+func (h *Handler) SetComplianceRules(r *metrics.ComplianceRules) { h.compliance.Store(r) }
+
+func (h *Handler) complianceRules() *metrics.ComplianceRules {
+	if r := h.compliance.Load(); r != nil {
+		return r
+	}
+	return metrics.DefaultComplianceRules()
+}
+
+// SetCertificateConfig define a janela de expiração de /metrics/certificates.
+func (h *Handler) SetCertificateConfig(cfg config.CertificateConfig) { h.certs.Store(&cfg) }
+
+func (h *Handler) certificateConfig() config.CertificateConfig {
+	if cfg := h.certs.Load(); cfg != nil {
+		return *cfg
+	}
+	return config.CertificateConfig{ExpiryWindow: config.DefaultCertificateExpiryWindow}
+}
+
+func (h *Handler) collectionConfig() config.CollectionConfig {
+	if cfg := h.settings.Load(); cfg != nil {
+		return *cfg
+	}
+	return config.CollectionConfig{Workers: config.DefaultCollectionWorkers, Timeout: config.DefaultCollectionTimeout}
 }
 
 // ClusterMetrics resposta JSON.
 type ClusterMetrics struct {
-	NodeCount       int            `json:"nodeCount"`
-	PodCount        int            `json:"podCount"`
-	DeploymentCount int            `json:"deploymentCount"`
-	ServiceCount    int            `json:"serviceCount"`
-	NamespaceCount  int            `json:"namespaceCount"`
-	PodPhases       map[string]int `json:"podPhases"`
-	Timestamp       time.Time      `json:"timestamp"`
+	Cluster string `json:"cluster,omitempty"`
+	metrics.Snapshot
+	// Sections estado da coleta de cada collector.
+	Sections map[string]Section `json:"sections"`
+	// Errors falhas da coleta; as seções afetadas ficam marcadas como stale.
+	Errors    []CollectionError `json:"errors"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// ObjectMetadata labels e annotations expostos de um objeto.
+type ObjectMetadata = metrics.ObjectMetadata
+
+// CustomMetrics resposta de /metrics/custom: objetos de cada recurso
+// customizado, pelo nome configurado.
+type CustomMetrics struct {
+	Resources map[string][]metrics.CustomObject `json:"resources"`
+	Sections  map[string]Section                `json:"sections"`
+	Errors    []CollectionError                 `json:"errors"`
+	Timestamp time.Time                         `json:"timestamp"`
+}
+
+// Certificates resposta de /metrics/certificates: certificados que expiram
+// dentro da janela configurada, ou já expirados, do mais próximo ao mais
+// distante.
+type Certificates struct {
+	ExpiryWindowSeconds float64               `json:"expiryWindowSeconds"`
+	Expiring            []metrics.Certificate `json:"expiring"`
+	Sections            map[string]Section    `json:"sections"`
+	Errors              []CollectionError     `json:"errors"`
+	Timestamp           time.Time             `json:"timestamp"`
+}
+
+// ImageInventory resposta de /metrics/images: imagens distintas em
+// execução, por cluster, com o resumo de registries, formas de referência e
+// violações da política.
+type ImageInventory struct {
+	Images []metrics.Image `json:"images"`
+	// Registries pods por registry; um pod com várias imagens do mesmo
+	// registry conta uma vez.
+	Registries map[string]int `json:"registries"`
+	Usage      ImageUsage     `json:"usage"`
+	// Violations pods fora da política, por motivo.
+	Violations map[string]int     `json:"violations"`
+	Sections   map[string]Section `json:"sections"`
+	Errors     []CollectionError  `json:"errors"`
+	Timestamp  time.Time          `json:"timestamp"`
+}
+
+// ImageUsage imagens distintas por forma de referência.
+type ImageUsage struct {
+	Images int `json:"images"`
+	// Digest imagens fixadas por digest; Tag, apenas por tag.
+	Digest int `json:"digest"`
+	Tag    int `json:"tag"`
+	// Latest imagens com a tag latest, explícita ou implícita.
+	Latest int `json:"latest"`
+}
+
+// ComplianceReport resposta de /compliance: violações das regras de
+// conformidade habilitadas, por workload, em todos os clusters.
+type ComplianceReport struct {
+	Rules []string `json:"rules"`
+	// Violations violações por regra, como em k8s_*_compliance_violations:
+	// pares pod/container nas regras de container (um pod com três containers
+	// sem limits conta três), pods em host_network e deployments em
+	// single_replica_without_pdb.
+	Violations map[string]int              `json:"violations"`
+	Findings   []metrics.ComplianceFinding `json:"findings"`
+	Sections   map[string]Section          `json:"sections"`
+	Errors     []CollectionError           `json:"errors"`
+	Timestamp  time.Time                   `json:"timestamp"`
 }
 
-// MetricsJSONHandler coleta e retorna métricas.
+// ClusterInfo item da listagem de clusters.
+type ClusterInfo struct {
+	Name string `json:"name"`
+}
+
+// MetricsJSONHandler coleta e retorna métricas agregadas de todos os clusters.
+// As métricas Prometheus são sempre atualizadas com o cluster inteiro; a
+// resposta JSON considera apenas os namespaces permitidos à identidade autenticada.
 func (h *Handler) MetricsJSONHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
-	defer cancel()
-	cs := h.k8s.Clientset
+	resp := rollup(h.collectAll(r, h.enabledCollectors()))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
 
-	nodes, err := cs.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		http.Error(w, err.Error(), 500)
-		return
+// CustomMetricsHandler coleta e retorna os recursos customizados
+// (CUSTOM_RESOURCES_FILE) de todos os clusters, com o mesmo filtro de
+// namespaces de MetricsJSONHandler. Apenas os collectors de recursos
+// customizados são executados, além do de namespaces, que aplica o filtro.
+func (h *Handler) CustomMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	var run, custom []metrics.Collector
+	for _, c := range h.enabledCollectors() {
+		if _, ok := c.(*metrics.CustomResourceCollector); ok {
+			custom = append(custom, c)
+			run = append(run, c)
+		} else if _, ok := c.(metrics.NamespaceCollector); ok {
+			run = append(run, c)
+		}
+	}
+	results := h.collectAll(r, run)
+
+	resp := CustomMetrics{Resources: map[string][]metrics.CustomObject{}, Sections: map[string]Section{}, Errors: []CollectionError{}, Timestamp: time.Now().UTC()}
+	for _, c := range custom {
+		resp.Resources[c.Name()] = []metrics.CustomObject{}
 	}
-	h.m.NodeCount.Set(float64(len(nodes.Items)))
-	h.m.NodeReady.Reset()
-	for _, n := range nodes.Items {
-		ready := false
-		for _, c := range n.Status.Conditions {
-			if c.Type == corev1.NodeReady && c.Status == corev1.ConditionTrue {
-				ready = true
-				break
+	for _, res := range results {
+		resp.Errors = append(resp.Errors, res.Errors...)
+		mergeSections(resp.Sections, res.Sections)
+		for name, objects := range res.Custom {
+			for _, o := range objects {
+				o.Cluster = res.Cluster
+				resp.Resources[name] = append(resp.Resources[name], o)
 			}
 		}
-		h.m.NodeReady.WithLabelValues(n.Name).Set(boolToFloat(ready))
-		h.m.CPUAllocatable.WithLabelValues(n.Name).Set(float64(n.Status.Allocatable.Cpu().MilliValue()) / 1000)
-		h.m.MemoryAllocatable.WithLabelValues(n.Name).Set(float64(n.Status.Allocatable.Memory().Value()))
 	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
 
-	pods, err := cs.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
-	if err != nil {
-		http.Error(w, err.Error(), 500)
-		return
+// CertificatesHandler coleta os certificados TLS (collectors tlssecrets e
+// certmanager) de todos os clusters e retorna os que expiram dentro de
+// TLS_EXPIRY_WINDOW, com o mesmo filtro de namespaces de MetricsJSONHandler.
+func (h *Handler) CertificatesHandler(w http.ResponseWriter, r *http.Request) {
+	var run []metrics.Collector
+	for _, c := range h.enabledCollectors() {
+		switch c.(type) {
+		case metrics.NamespaceCollector, metrics.TLSSecretCollector, metrics.CertManagerCollector:
+			run = append(run, c)
+		}
 	}
-	h.m.PodCount.Set(float64(len(pods.Items)))
-	h.m.PodStatus.Reset()
-	h.m.ContainerRestarts.Reset()
-	podPhases := map[string]int{}
-	cpuReq := map[string]float64{}
-	memReq := map[string]float64{}
-	cpuLim := map[string]float64{}
-	memLim := map[string]float64{}
-	for _, p := range pods.Items {
-		phase := string(p.Status.Phase)
-		podPhases[phase]++
-		h.m.PodStatus.WithLabelValues(p.Namespace, phase).Inc()
-		for _, cs := range p.Status.ContainerStatuses {
-			h.m.ContainerRestarts.WithLabelValues(p.Namespace, p.Name, cs.Name).Set(float64(cs.RestartCount))
-		}
-		for _, c := range p.Spec.Containers {
-			if q, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
-				cpuReq[p.Namespace] += float64(q.MilliValue()) / 1000
+	results := h.collectAll(r, run)
+
+	window := h.certificateConfig().ExpiryWindow
+	now := time.Now().UTC()
+	resp := Certificates{ExpiryWindowSeconds: window.Seconds(), Expiring: []metrics.Certificate{}, Sections: map[string]Section{}, Errors: []CollectionError{}, Timestamp: now}
+	for _, res := range results {
+		resp.Errors = append(resp.Errors, res.Errors...)
+		mergeSections(resp.Sections, res.Sections)
+		for _, c := range res.Certificates {
+			if c.ExpiresWithin(now, window) {
+				c.Cluster = res.Cluster
+				resp.Expiring = append(resp.Expiring, c)
 			}
-			if q, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
-				memReq[p.Namespace] += float64(q.Value())
+		}
+	}
+	sort.SliceStable(resp.Expiring, func(i, j int) bool { return resp.Expiring[i].NotAfter.Before(resp.Expiring[j].NotAfter) })
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// ImagesHandler coleta os pods de todos os clusters e retorna o inventário de
+// imagens, com o mesmo filtro de namespaces de MetricsJSONHandler.
+func (h *Handler) ImagesHandler(w http.ResponseWriter, r *http.Request) {
+	var run []metrics.Collector
+	for _, c := range h.enabledCollectors() {
+		switch c.(type) {
+		case metrics.NamespaceCollector, metrics.PodCollector:
+			run = append(run, c)
+		}
+	}
+	results := h.collectAll(r, run)
+
+	resp := ImageInventory{
+		Images: []metrics.Image{}, Registries: map[string]int{}, Violations: map[string]int{},
+		Sections: map[string]Section{}, Errors: []CollectionError{}, Timestamp: time.Now().UTC(),
+	}
+	seen := map[string]bool{}
+	for _, res := range results {
+		resp.Errors = append(resp.Errors, res.Errors...)
+		mergeSections(resp.Sections, res.Sections)
+		for reason, n := range res.ImageViolations {
+			resp.Violations[reason] += n
+		}
+		for registry, n := range res.ImageRegistries {
+			resp.Registries[registry] += n
+		}
+		for _, img := range res.Images {
+			img.Cluster = res.Cluster
+			resp.Images = append(resp.Images, img)
+			if seen[img.Image] {
+				continue
 			}
-			if q, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
-				cpuLim[p.Namespace] += float64(q.MilliValue()) / 1000
+			seen[img.Image] = true
+			resp.Usage.Images++
+			if img.Digest != "" {
+				resp.Usage.Digest++
+			} else {
+				resp.Usage.Tag++
 			}
-			if q, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
-				memLim[p.Namespace] += float64(q.Value())
+			if img.Latest {
+				resp.Usage.Latest++
 			}
 		}
 	}
-	h.m.CPURequests.Reset()
-	h.m.MemoryRequests.Reset()
-	h.m.CPULimits.Reset()
-	h.m.MemoryLimits.Reset()
-	for ns, v := range cpuReq {
-		h.m.CPURequests.WithLabelValues(ns).Set(v)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// ComplianceHandler coleta pods e deployments de todos os clusters e retorna
+// as violações das regras de conformidade, com o mesmo filtro de namespaces
+// de MetricsJSONHandler.
+func (h *Handler) ComplianceHandler(w http.ResponseWriter, r *http.Request) {
+	var run []metrics.Collector
+	for _, c := range h.enabledCollectors() {
+		switch c.(type) {
+		case metrics.NamespaceCollector, metrics.PodCollector, metrics.DeploymentCollector:
+			run = append(run, c)
+		}
 	}
-	for ns, v := range memReq {
-		h.m.MemoryRequests.WithLabelValues(ns).Set(v)
+	results := h.collectAll(r, run)
+
+	rules := h.complianceRules().Names()
+	resp := ComplianceReport{
+		Rules: rules, Violations: map[string]int{}, Findings: []metrics.ComplianceFinding{},
+		Sections: map[string]Section{}, Errors: []CollectionError{}, Timestamp: time.Now().UTC(),
 	}
-	for ns, v := range cpuLim {
-		h.m.CPULimits.WithLabelValues(ns).Set(v)
+	for _, rule := range rules {
+		resp.Violations[rule] = 0
 	}
-	for ns, v := range memLim {
-		h.m.MemoryLimits.WithLabelValues(ns).Set(v)
+	for _, res := range results {
+		resp.Errors = append(resp.Errors, res.Errors...)
+		mergeSections(resp.Sections, res.Sections)
+		for _, f := range res.Compliance {
+			f.Cluster = res.Cluster
+			resp.Findings = append(resp.Findings, f)
+			resp.Violations[f.Rule] += max(f.Pods, 1)
+		}
 	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
 
-	namespaces, err := cs.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		http.Error(w, err.Error(), 500)
-		return
-	}
-	h.m.NamespaceCount.Set(float64(len(namespaces.Items)))
+// collectAll executa os collectors em todos os clusters em paralelo, sob o
+// prazo de COLLECTION_TIMEOUT, e registra as falhas no log.
+func (h *Handler) collectAll(r *http.Request, collectors []metrics.Collector) []ClusterMetrics {
+	ctx, cancel := context.WithTimeout(r.Context(), h.collectionConfig().Timeout)
+	defer cancel()
 
-	deployments, err := cs.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
-	if err != nil {
-		http.Error(w, err.Error(), 500)
-		return
+	results := make([]ClusterMetrics, len(h.clusters))
+	var wg sync.WaitGroup
+	for i := range h.clusters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = h.collect(ctx, h.clusters[i], allowedNamespaces(r), collectors)
+			h.logErrors(ctx, results[i].Errors)
+		}(i)
 	}
-	h.m.DeploymentCount.Set(float64(len(deployments.Items)))
-	h.m.DeploymentDesired.Reset()
-	h.m.DeploymentAvailable.Reset()
-	for _, d := range deployments.Items {
-		h.m.DeploymentDesired.WithLabelValues(d.Namespace, d.Name).Set(float64(*d.Spec.Replicas))
-		h.m.DeploymentAvailable.WithLabelValues(d.Namespace, d.Name).Set(float64(d.Status.AvailableReplicas))
+	wg.Wait()
+	return results
+}
+
+// ClustersHandler lista os clusters configurados.
+func (h *Handler) ClustersHandler(w http.ResponseWriter, _ *http.Request) {
+	out := make([]ClusterInfo, 0, len(h.clusters))
+	for _, c := range h.clusters {
+		out = append(out, ClusterInfo{Name: c.Name})
 	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
 
-	services, err := cs.CoreV1().Services("").List(ctx, metav1.ListOptions{})
-	if err != nil {
-		http.Error(w, err.Error(), 500)
+// ClusterMetricsHandler coleta e retorna as métricas de um cluster ({name} no path).
+func (h *Handler) ClusterMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.cluster(r.PathValue("name"))
+	if !ok {
+		http.Error(w, "cluster not found", http.StatusNotFound)
 		return
 	}
-	h.m.ServiceCount.Set(float64(len(services.Items)))
-
-	resp := ClusterMetrics{NodeCount: len(nodes.Items), PodCount: len(pods.Items), DeploymentCount: len(deployments.Items), ServiceCount: len(services.Items), NamespaceCount: len(namespaces.Items), PodPhases: podPhases, Timestamp: time.Now().UTC()}
+	ctx, cancel := context.WithTimeout(r.Context(), h.collectionConfig().Timeout)
+	defer cancel()
+	resp := h.collect(ctx, c, allowedNamespaces(r), h.enabledCollectors())
+	h.logErrors(ctx, resp.Errors)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+func (h *Handler) cluster(name string) (Cluster, bool) {
+	for _, c := range h.clusters {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Cluster{}, false
+}
+
+func (h *Handler) logErrors(ctx context.Context, errs []CollectionError) {
+	for _, e := range errs {
+		h.log.ErrorContext(ctx, "Erro ao coletar métricas", "cluster", e.Cluster, "resource", e.Resource, "error", e.Error)
+	}
+}
+
+func allowedNamespaces(r *http.Request) func(string) bool {
+	if id, ok := middleware.IdentityFrom(r.Context()); ok {
+		return id.AllowsNamespace
+	}
+	return func(string) bool { return true }
+}
+
+// rollup soma as métricas de vários clusters. Com um único cluster, a resposta
+// é a do próprio cluster; com vários, labels, annotations e as listas de
+// services e Ingresses ficam apenas em /clusters/{name}/metrics. As seções são combinadas por mergeSections.
+func rollup(results []ClusterMetrics) ClusterMetrics {
+	if len(results) == 1 {
+		return results[0]
+	}
+	out := ClusterMetrics{Sections: map[string]Section{}, Errors: []CollectionError{}, Timestamp: time.Now().UTC()}
+	out.PodPhases = map[string]int{}
+	for _, r := range results {
+		out.Errors = append(out.Errors, r.Errors...)
+		mergeSections(out.Sections, r.Sections)
+		out.NodeCount += r.NodeCount
+		out.PodCount += r.PodCount
+		out.DeploymentCount += r.DeploymentCount
+		out.ServiceCount += r.ServiceCount
+		out.NamespaceCount += r.NamespaceCount
+		for phase, n := range r.PodPhases {
+			out.PodPhases[phase] += n
+		}
+		for class, n := range r.PodQOSClasses {
+			if out.PodQOSClasses == nil {
+				out.PodQOSClasses = map[string]int{}
+			}
+			out.PodQOSClasses[class] += n
+		}
+		for class, n := range r.PodPriorityClasses {
+			if out.PodPriorityClasses == nil {
+				out.PodPriorityClasses = map[string]int{}
+			}
+			out.PodPriorityClasses[class] += n
+		}
+		for typ, n := range r.ServicesByType {
+			if out.ServicesByType == nil {
+				out.ServicesByType = map[string]int{}
+			}
+			out.ServicesByType[typ] += n
+		}
+	}
+	return out
+}
+
+// mergeSections acumula as seções de um cluster em dst: stale se estiver
+// stale em algum cluster, com o CollectedAt mais antigo e a maior duração.
+func mergeSections(dst, src map[string]Section) {
+	for name, sec := range src {
+		cur, seen := dst[name]
+		if seen && !cur.CollectedAt.After(sec.CollectedAt) {
+			sec.CollectedAt = cur.CollectedAt
+		}
+		sec.Stale = sec.Stale || cur.Stale
+		sec.DurationSeconds = max(sec.DurationSeconds, cur.DurationSeconds)
+		dst[name] = sec
+	}
+}
+
 // HealthCheckHandler simples.
 func (h *Handler) HealthCheckHandler(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok", "ts": time.Now().UTC().Format(time.RFC3339)})
 }
-
-func boolToFloat(b bool) float64 {
-	if b {
-		return 1
-	}
-	return 0
-}
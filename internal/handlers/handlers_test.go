@@ -1,23 +1,42 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
+	"k8s-metrics-api/internal/config"
 	"k8s-metrics-api/internal/k8s"
 	"k8s-metrics-api/internal/metrics"
+	"k8s-metrics-api/internal/middleware"
 )
 
 // newTestClient creates a k8s.Client with fake clientset for testing
@@ -171,3 +190,755 @@ func TestMetricsJSONHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricsJSONHandlerRestrictsNamespaces(t *testing.T) {
+	// Arrange
+	k8sClient := newTestClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-b"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a1", Namespace: "app-a"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b1", Namespace: "app-b"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc-b", Namespace: "app-b"}},
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	handler := New(k8sClient, metrics.NewPrometheusMetrics(logger), logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req = req.WithContext(middleware.WithIdentity(req.Context(), &middleware.Identity{Name: "alice", Namespaces: []string{"app-a"}}))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.MetricsJSONHandler(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response ClusterMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.PodCount)
+	assert.Equal(t, 1, response.NamespaceCount)
+	assert.Equal(t, 0, response.ServiceCount)
+	assert.Equal(t, map[string]int{"Running": 1}, response.PodPhases)
+}
+
+func newTestFleet(t *testing.T) *Handler {
+	t.Helper()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	reg := prometheus.NewRegistry()
+	east := newTestClient(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "east-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+	)
+	west := newTestClient(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "west-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "west-2"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+	)
+	return NewMulti([]Cluster{
+		{Name: "east", Client: east, Metrics: metrics.NewClusterMetrics(reg, "east", logger)},
+		{Name: "west", Client: west, Metrics: metrics.NewClusterMetrics(reg, "west", logger)},
+	}, logger)
+}
+
+func TestMultiClusterHandlers(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		assertBody     func(t *testing.T, body []byte)
+	}{
+		{
+			name:           "should roll up metrics across clusters",
+			path:           "/metrics",
+			expectedStatus: http.StatusOK,
+			assertBody: func(t *testing.T, body []byte) {
+				var resp ClusterMetrics
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, 3, resp.NodeCount)
+				assert.Equal(t, 2, resp.PodCount)
+				assert.Equal(t, map[string]int{"Running": 1, "Pending": 1}, resp.PodPhases)
+				assert.Equal(t, map[string]int{"BestEffort": 2}, resp.PodQOSClasses)
+				assert.Equal(t, map[string]int{"none": 2}, resp.PodPriorityClasses)
+			},
+		},
+		{
+			name:           "should list clusters",
+			path:           "/clusters",
+			expectedStatus: http.StatusOK,
+			assertBody: func(t *testing.T, body []byte) {
+				var resp []ClusterInfo
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, []ClusterInfo{{Name: "east"}, {Name: "west"}}, resp)
+			},
+		},
+		{
+			name:           "should return metrics of a single cluster",
+			path:           "/clusters/west/metrics",
+			expectedStatus: http.StatusOK,
+			assertBody: func(t *testing.T, body []byte) {
+				var resp ClusterMetrics
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, "west", resp.Cluster)
+				assert.Equal(t, 2, resp.NodeCount)
+				assert.Equal(t, 1, resp.PodCount)
+			},
+		},
+		{
+			name:           "should return 404 for unknown cluster",
+			path:           "/clusters/north/metrics",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			h := newTestFleet(t)
+			mux := http.NewServeMux()
+			mux.HandleFunc("/metrics", h.MetricsJSONHandler)
+			mux.HandleFunc("GET /clusters", h.ClustersHandler)
+			mux.HandleFunc("GET /clusters/{name}/metrics", h.ClusterMetricsHandler)
+			w := httptest.NewRecorder()
+
+			// Act
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.assertBody != nil {
+				tt.assertBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestMetricsJSONHandlerAppliesNamespaceFilter(t *testing.T) {
+	tests := []struct {
+		name               string
+		filter             config.NamespaceFilterConfig
+		expectedPods       int
+		expectedNamespaces int
+		expectedRestarts   int
+	}{
+		{
+			name:               "should exclude namespaces by regex",
+			filter:             config.NamespaceFilterConfig{ExcludeRegex: "ci-.*"},
+			expectedPods:       2,
+			expectedNamespaces: 1,
+			expectedRestarts:   2,
+		},
+		{
+			name:               "should collect only included namespaces",
+			filter:             config.NamespaceFilterConfig{Include: []string{"ci-1"}},
+			expectedPods:       1,
+			expectedNamespaces: 1,
+			expectedRestarts:   1,
+		},
+		{
+			name:               "should select namespaces by label",
+			filter:             config.NamespaceFilterConfig{LabelSelector: "team=platform"},
+			expectedPods:       2,
+			expectedNamespaces: 1,
+			expectedRestarts:   2,
+		},
+		{
+			name:               "should select pods by label",
+			filter:             config.NamespaceFilterConfig{PodLabelSelector: "app=web"},
+			expectedPods:       1,
+			expectedNamespaces: 3,
+			expectedRestarts:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			pod := func(name, ns, app string) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: map[string]string{"app": app}},
+					Status:     corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{Name: "c"}}},
+				}
+			}
+			k8sClient := newTestClient(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"team": "platform"}}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ci-1"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ci-2"}},
+				pod("web", "prod", "web"),
+				pod("worker", "prod", "worker"),
+				pod("job", "ci-1", "job"),
+				pod("job", "ci-2", "job"),
+			)
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			m := metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+			handler := New(k8sClient, m, logger)
+			filter, err := k8s.NewNamespaceFilter(tt.filter)
+			require.NoError(t, err)
+			handler.SetNamespaceFilter(filter)
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.MetricsJSONHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			// Assert
+			require.Equal(t, http.StatusOK, w.Code)
+			var response ClusterMetrics
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedPods, response.PodCount)
+			assert.Equal(t, tt.expectedNamespaces, response.NamespaceCount)
+			assert.Equal(t, tt.expectedRestarts, testutil.CollectAndCount(m.ContainerRestarts))
+		})
+	}
+}
+
+func TestMetricsJSONHandlerExposesAllowedMetadata(t *testing.T) {
+	// Arrange
+	k8sClient := newTestClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "prod",
+			Labels:      map[string]string{"team": "platform", "cost-center": "42", "kubernetes.io/metadata.name": "prod"},
+			Annotations: map[string]string{"owner": "sre@example.com"},
+		}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "a"}}},
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	reg := prometheus.NewRegistry()
+	m := metrics.NewClusterMetrics(reg, "default", logger, metrics.WithMetadataAllowlist(config.MetadataAllowlistConfig{
+		Labels:      map[string][]string{config.ResourceNamespaces: {"team", "cost-center"}},
+		Annotations: map[string][]string{config.ResourceNamespaces: {"*"}},
+	}))
+	handler := New(k8sClient, m, logger)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.MetricsJSONHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response ClusterMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string]ObjectMetadata{"prod": {
+		Labels:      map[string]string{"team": "platform", "cost-center": "42"},
+		Annotations: map[string]string{"owner": "sre@example.com"},
+	}}, response.Namespaces)
+	assert.Empty(t, response.Nodes)
+	expected := `
+# HELP k8s_namespace_labels Labels Kubernetes do namespace
+# TYPE k8s_namespace_labels gauge
+k8s_namespace_labels{cluster="default",label_cost_center="42",label_team="platform",namespace="prod"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "k8s_namespace_labels"))
+}
+
+func TestCollectAggregatesByWorkload(t *testing.T) {
+	// Arrange
+	controller := func(kind, name string) []metav1.OwnerReference {
+		isController := true
+		return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: &isController}}
+	}
+	pod := func(name string, ready bool, restarts int32) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod", OwnerReferences: controller("ReplicaSet", "web-7d9f")},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+			}}}},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: restarts}},
+			},
+		}
+	}
+	k8sClient := newTestClient(
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-7d9f", Namespace: "prod", OwnerReferences: controller("Deployment", "web")}},
+		pod("web-7d9f-a", true, 1),
+		pod("web-7d9f-b", false, 2),
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	handler := New(k8sClient, m, logger)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.MetricsJSONHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	labels := []string{"prod", "Deployment", "web"}
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.WorkloadPods.WithLabelValues("prod", "Deployment", "web", "Running")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.WorkloadPodsReady.WithLabelValues(labels...)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.WorkloadRestarts.WithLabelValues(labels...)))
+	assert.Equal(t, 0.5, testutil.ToFloat64(m.WorkloadCPURequests.WithLabelValues(labels...)))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.WorkloadRestarts))
+}
+
+func TestCollectRecordsCollectorMetrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		failResource   string
+		expectedStatus int
+		expectedErrors float64
+		expectedPods   float64
+	}{
+		{
+			name:           "should count processed objects and mark success",
+			expectedStatus: http.StatusOK,
+			expectedPods:   2,
+		},
+		{
+			name:           "should count api errors by verb and resource",
+			failResource:   "services",
+			expectedStatus: http.StatusOK,
+			expectedErrors: 1,
+			expectedPods:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			k8sClient := newTestClient(
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default"}},
+			)
+			if tt.failResource != "" {
+				k8sClient.Clientset.(*fake.Clientset).PrependReactor("list", tt.failResource, func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("boom")
+				})
+			}
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			m := metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+			handler := New(k8sClient, m, logger)
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.MetricsJSONHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			// Assert
+			require.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedPods, testutil.ToFloat64(m.Collector.Objects.WithLabelValues("pods")))
+			assert.Equal(t, tt.expectedErrors, testutil.ToFloat64(m.Collector.APIErrors.WithLabelValues(metrics.VerbList, "services")))
+			assert.Equal(t, tt.failResource == "", testutil.ToFloat64(m.Collector.LastSuccess) > 0)
+		})
+	}
+}
+
+func TestCollectReturnsPartialResults(t *testing.T) {
+	// Arrange
+	k8sClient := newTestClient(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}},
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	handler := New(k8sClient, m, logger)
+	handler.MetricsJSONHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	clientset := k8sClient.Clientset.(*fake.Clientset)
+	clientset.PrependReactor("list", "services", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("services is forbidden")
+	})
+	clientset.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("nodes is forbidden")
+	})
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.MetricsJSONHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response ClusterMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.PodCount)
+	assert.Equal(t, 1, response.ServiceCount, "services should come from the last successful list")
+	assert.False(t, response.Sections["pods"].Stale)
+	assert.True(t, response.Sections["services"].Stale)
+	assert.False(t, response.Sections["services"].CollectedAt.IsZero())
+	assert.True(t, response.Sections["nodes"].Stale)
+	assert.ElementsMatch(t, []CollectionError{
+		{Resource: "services", Error: "services is forbidden"},
+		{Resource: "nodes", Error: "nodes is forbidden"},
+	}, response.Errors)
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.Collector.Up.WithLabelValues("services")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.Collector.Up.WithLabelValues("pods")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.ServiceCount))
+}
+
+func TestCollectPaginatesLists(t *testing.T) {
+	// Arrange
+	var pods []corev1.Pod
+	for i := range 5 {
+		pods = append(pods, corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-" + strconv.Itoa(i), Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		})
+	}
+	k8sClient := newTestClient()
+	k8sClient.PageSize = 2
+	var requests []metav1.ListOptions
+	k8sClient.Clientset.(*fake.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.ListActionImpl).GetListOptions()
+		requests = append(requests, opts)
+		start, _ := strconv.Atoi(opts.Continue)
+		end := min(start+int(opts.Limit), len(pods))
+		list := &corev1.PodList{Items: pods[start:end]}
+		if end < len(pods) {
+			list.Continue = strconv.Itoa(end)
+		}
+		return true, list, nil
+	})
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	handler := New(k8sClient, m, logger)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.MetricsJSONHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response ClusterMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 5, response.PodCount)
+	require.Len(t, requests, 3)
+	assert.Equal(t, int64(2), requests[0].Limit)
+	assert.Equal(t, []string{"", "2", "4"}, []string{requests[0].Continue, requests[1].Continue, requests[2].Continue})
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.Collector.Objects.WithLabelValues("pods")))
+}
+
+func TestCollectRunsListsConcurrently(t *testing.T) {
+	const latency = 20 * time.Millisecond
+	tests := []struct {
+		name             string
+		workers          int
+		expectedInFlight int32
+	}{
+		{name: "should run lists sequentially with one worker", workers: 1, expectedInFlight: 1},
+		{name: "should bound concurrent lists by workers", workers: 3, expectedInFlight: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			k8sClient := newTestClient(
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+				&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc-1", Namespace: "default"}},
+			)
+			fakeClientset := k8sClient.Clientset.(*fake.Clientset)
+			var inFlight, maxInFlight atomic.Int32
+			fakeClientset.PrependReactor("list", "*", func(k8stesting.Action) (bool, runtime.Object, error) {
+				n := inFlight.Add(1)
+				for cur := maxInFlight.Load(); n > cur && !maxInFlight.CompareAndSwap(cur, n); cur = maxInFlight.Load() {
+				}
+				// O fake serializa as chamadas; a latência é simulada fora do lock.
+				fakeClientset.Unlock()
+				time.Sleep(latency)
+				fakeClientset.Lock()
+				inFlight.Add(-1)
+				return false, nil, nil
+			})
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			handler := New(k8sClient, metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger), logger)
+			handler.SetCollectionConfig(config.CollectionConfig{Workers: tt.workers, Timeout: time.Second})
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.MetricsJSONHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			// Assert
+			require.Equal(t, http.StatusOK, w.Code)
+			var response ClusterMetrics
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedInFlight, maxInFlight.Load())
+			assert.Equal(t, 1, response.NodeCount)
+			assert.Equal(t, 1, response.NamespaceCount)
+			assert.Equal(t, 1, response.PodCount)
+			assert.Equal(t, 1, response.ServiceCount)
+			assert.Empty(t, response.Errors)
+			for _, resource := range []string{"namespaces", "nodes", "pods", "deployments", "services"} {
+				require.Contains(t, response.Sections, resource)
+				assert.GreaterOrEqual(t, response.Sections[resource].DurationSeconds, latency.Seconds(), resource)
+			}
+		})
+	}
+}
+
+// failingCollector collector que sempre falha ao listar.
+type failingCollector struct{}
+
+func (failingCollector) Name() string               { return "statefulsets" }
+func (failingCollector) Rules() []rbacv1.PolicyRule { return nil }
+func (failingCollector) Describe() []string         { return nil }
+func (failingCollector) Collect(context.Context, *metrics.Source) (metrics.Result, error) {
+	return nil, errors.New("forbidden")
+}
+
+// overlapCollector collector cujo Apply zera e soma uma série com uma pausa
+// no meio, registrando quantos Apply rodam ao mesmo tempo.
+type overlapCollector struct{ inFlight, maxInFlight *atomic.Int32 }
+
+func (overlapCollector) Name() string               { return "overlap" }
+func (overlapCollector) Rules() []rbacv1.PolicyRule { return nil }
+func (overlapCollector) Describe() []string         { return nil }
+func (c overlapCollector) Collect(context.Context, *metrics.Source) (metrics.Result, error) {
+	return c, nil
+}
+
+func (c overlapCollector) Apply(_ *metrics.Snapshot, m *metrics.PrometheusMetrics) {
+	n := c.inFlight.Add(1)
+	for cur := c.maxInFlight.Load(); n > cur && !c.maxInFlight.CompareAndSwap(cur, n); cur = c.maxInFlight.Load() {
+	}
+	m.PodStatus.Reset()
+	time.Sleep(10 * time.Millisecond)
+	m.PodStatus.WithLabelValues("default", "Running").Add(1)
+	c.inFlight.Add(-1)
+}
+
+func TestCollectSerializesApply(t *testing.T) {
+	// Arrange
+	var inFlight, maxInFlight atomic.Int32
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	handler := New(newTestClient(), m, logger)
+	handler.SetCollectors([]metrics.Collector{overlapCollector{&inFlight, &maxInFlight}})
+
+	// Act
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.MetricsJSONHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, int32(1), maxInFlight.Load())
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.PodStatus.WithLabelValues("default", "Running")))
+}
+
+func TestCollectRunsConfiguredCollectors(t *testing.T) {
+	// Arrange
+	k8sClient := newTestClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc-1", Namespace: "default"}},
+	)
+	registry := metrics.DefaultRegistry()
+	registry.Register(failingCollector{})
+	collectors, err := registry.Select(nil, []string{"services", "pods"})
+	require.NoError(t, err)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	handler := New(k8sClient, metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger), logger)
+	handler.SetCollectors(collectors)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.MetricsJSONHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response ClusterMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.NamespaceCount)
+	assert.Zero(t, response.ServiceCount)
+	assert.ElementsMatch(t, []string{"namespaces", "nodes", "deployments", "ingresses", "tlssecrets", "statefulsets"}, slices.Collect(maps.Keys(response.Sections)))
+	assert.True(t, response.Sections["statefulsets"].Stale)
+	assert.Equal(t, []CollectionError{{Resource: "statefulsets", Error: "forbidden"}}, response.Errors)
+}
+
+func TestCustomMetricsHandler(t *testing.T) {
+	// Arrange
+	cr := config.CustomResourceConfig{
+		Name: "certificate", Group: "cert-manager.io", Version: "v1", Resource: "certificates",
+		Metrics: []config.CustomMetricConfig{{Name: "expiration_timestamp_seconds", Path: "status.notAfter"}},
+	}
+	gvr := schema.GroupVersionResource{Group: cr.Group, Version: cr.Version, Resource: cr.Resource}
+	certificate := func(ns, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]any{"namespace": ns, "name": name},
+			"status":     map[string]any{"notAfter": "2025-08-20T18:30:00Z"},
+		}}
+	}
+	k8sClient := newTestClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+	)
+	k8sClient.Name = "default"
+	k8sClient.Dynamic = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "CertificateList"},
+		certificate("prod", "api-tls"), certificate("dev", "dev-tls"))
+	registry := metrics.DefaultRegistry()
+	require.NoError(t, registry.RegisterCustomResources([]config.CustomResourceConfig{cr}))
+	collectors, err := registry.Select(nil, nil)
+	require.NoError(t, err)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	reg := prometheus.NewRegistry()
+	handler := New(k8sClient, metrics.NewClusterMetrics(reg, "default", logger, metrics.WithCustomResources([]config.CustomResourceConfig{cr})), logger)
+	handler.SetCollectors(collectors)
+	req := httptest.NewRequest(http.MethodGet, "/metrics/custom", nil)
+	req = req.WithContext(middleware.WithIdentity(req.Context(), &middleware.Identity{Name: "team", Namespaces: []string{"prod"}}))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.CustomMetricsHandler(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response CustomMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []metrics.CustomObject{{
+		Cluster: "default", Namespace: "prod", Name: "api-tls",
+		Values: map[string]float64{"expiration_timestamp_seconds": 1755714600},
+	}}, response.Resources["certificate"])
+	assert.ElementsMatch(t, []string{"namespaces", "certificate"}, slices.Collect(maps.Keys(response.Sections)))
+	assert.Empty(t, response.Errors)
+	// As séries Prometheus consideram todos os namespaces coletados.
+	count, err := testutil.GatherAndCount(reg, "k8s_custom_certificate_expiration_timestamp_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestCertificatesHandler(t *testing.T) {
+	// Arrange
+	now := time.Now().UTC().Truncate(time.Second)
+	gvr := schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	certificate := func(ns, name string, notAfter time.Time) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]any{"namespace": ns, "name": name},
+			"status":     map[string]any{"notAfter": notAfter.Format(time.RFC3339)},
+		}}
+	}
+	k8sClient := newTestClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+	)
+	k8sClient.Name = "default"
+	k8sClient.Dynamic = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "CertificateList"},
+		certificate("prod", "soon", now.Add(48*time.Hour)),
+		certificate("prod", "expired", now.Add(-time.Hour)),
+		certificate("prod", "later", now.Add(90*24*time.Hour)),
+		certificate("dev", "dev-soon", now.Add(time.Hour)),
+	)
+	registry := metrics.DefaultRegistry()
+	registry.Register(metrics.CertManagerCollector{})
+	collectors, err := registry.Select(nil, nil)
+	require.NoError(t, err)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	reg := prometheus.NewRegistry()
+	handler := New(k8sClient, metrics.NewClusterMetrics(reg, "default", logger), logger)
+	handler.SetCollectors(collectors)
+	handler.SetCertificateConfig(config.CertificateConfig{ExpiryWindow: 7 * 24 * time.Hour})
+	req := httptest.NewRequest(http.MethodGet, "/metrics/certificates", nil)
+	req = req.WithContext(middleware.WithIdentity(req.Context(), &middleware.Identity{Name: "team", Namespaces: []string{"prod"}}))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.CertificatesHandler(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response Certificates
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, (7 * 24 * time.Hour).Seconds(), response.ExpiryWindowSeconds)
+	require.Len(t, response.Expiring, 2)
+	assert.Equal(t, "expired", response.Expiring[0].Name)
+	assert.Equal(t, "soon", response.Expiring[1].Name)
+	assert.Equal(t, "default", response.Expiring[1].Cluster)
+	assert.ElementsMatch(t, []string{"namespaces", "tlssecrets", "certmanager"}, slices.Collect(maps.Keys(response.Sections)))
+	assert.Empty(t, response.Errors)
+	// As séries Prometheus consideram todos os certificados coletados.
+	count, err := testutil.GatherAndCount(reg, "k8s_certmanager_certificate_expiry_timestamp_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
+
+func TestImagesHandler(t *testing.T) {
+	// Arrange
+	pod := func(ns, name string, images ...string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+		for i, img := range images {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: "c" + strconv.Itoa(i), Image: img})
+		}
+		return p
+	}
+	k8sClient := newTestClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		pod("prod", "api-1", "ghcr.io/acme/api@sha256:abc", "nginx"),
+		pod("prod", "api-2", "ghcr.io/acme/api@sha256:abc", "ghcr.io/acme/proxy:1.0"),
+		pod("prod", "web-1", "nginx:1.27"),
+	)
+	k8sClient.Name = "default"
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	handler := New(k8sClient, metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger), logger)
+	handler.SetImagePolicy(metrics.NewImagePolicy(config.ImagePolicyConfig{DisallowedTags: []string{"latest"}}))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ImagesHandler(w, httptest.NewRequest(http.MethodGet, "/metrics/images", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response ImageInventory
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Images, 4)
+	assert.Equal(t, "default", response.Images[0].Cluster)
+	assert.Equal(t, ImageUsage{Images: 4, Digest: 1, Tag: 3, Latest: 1}, response.Usage)
+	// api-2 tem duas imagens do ghcr.io e conta uma vez.
+	assert.Equal(t, map[string]int{"ghcr.io": 2, "docker.io": 2}, response.Registries)
+	assert.Equal(t, map[string]int{metrics.ViolationTag: 1}, response.Violations)
+	assert.ElementsMatch(t, []string{"namespaces", "pods"}, slices.Collect(maps.Keys(response.Sections)))
+	assert.Empty(t, response.Errors)
+}
+
+func TestComplianceHandler(t *testing.T) {
+	// Arrange
+	privileged := true
+	replicas := int32(1)
+	k8sClient := newTestClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "debug"},
+			Spec: corev1.PodSpec{HostNetwork: true, Containers: []corev1.Container{
+				{Name: "shell", SecurityContext: &corev1.SecurityContext{Privileged: &privileged}},
+				{Name: "sidecar", SecurityContext: &corev1.SecurityContext{Privileged: &privileged}},
+				{Name: "proxy"},
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "api"}, Spec: appsv1.DeploymentSpec{Replicas: &replicas}},
+	)
+	k8sClient.Name = "default"
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	handler := New(k8sClient, metrics.NewClusterMetrics(prometheus.NewRegistry(), "default", logger), logger)
+	rules, err := metrics.NewComplianceRules([]string{metrics.RulePrivileged, metrics.RuleHostNetwork, metrics.RuleSingleReplicaWithoutPDB}, nil)
+	require.NoError(t, err)
+	handler.SetComplianceRules(rules)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ComplianceHandler(w, httptest.NewRequest(http.MethodGet, "/compliance", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response ComplianceReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{metrics.RulePrivileged, metrics.RuleHostNetwork, metrics.RuleSingleReplicaWithoutPDB}, response.Rules)
+	// Regras de container contam pares pod/container.
+	assert.Equal(t, map[string]int{metrics.RulePrivileged: 2, metrics.RuleHostNetwork: 1, metrics.RuleSingleReplicaWithoutPDB: 1}, response.Violations)
+	assert.Equal(t, []metrics.ComplianceFinding{
+		{Cluster: "default", Namespace: "prod", Kind: "Deployment", Name: "api", Rule: metrics.RuleSingleReplicaWithoutPDB},
+		{Cluster: "default", Namespace: "prod", Kind: "Pod", Name: "debug", Rule: metrics.RuleHostNetwork, Pods: 1},
+		{Cluster: "default", Namespace: "prod", Kind: "Pod", Name: "debug", Container: "shell", Rule: metrics.RulePrivileged, Pods: 1},
+		{Cluster: "default", Namespace: "prod", Kind: "Pod", Name: "debug", Container: "sidecar", Rule: metrics.RulePrivileged, Pods: 1},
+	}, response.Findings)
+	assert.ElementsMatch(t, []string{"namespaces", "pods", "deployments"}, slices.Collect(maps.Keys(response.Sections)))
+	assert.Empty(t, response.Errors)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// LogLevel corpo de /admin/loglevel.
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// LogLevelHandler consulta (GET) ou altera (PUT) o nível de log em tempo de execução.
+func LogLevelHandler(level *slog.LevelVar, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var req LogLevel
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+				http.Error(w, "invalid body", http.StatusBadRequest)
+				return
+			}
+			var next slog.Level
+			if err := next.UnmarshalText([]byte(req.Level)); err != nil {
+				http.Error(w, "invalid level", http.StatusBadRequest)
+				return
+			}
+			old := level.Level()
+			level.Set(next)
+			logger.InfoContext(r.Context(), "Nível de log alterado", "old", old, "new", next)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(LogLevel{Level: strings.ToLower(level.Level().String())})
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedLevel  slog.Level
+		expectedBody   string
+	}{
+		{
+			name:           "should return current level",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedLevel:  slog.LevelInfo,
+			expectedBody:   `{"level":"info"}`,
+		},
+		{
+			name:           "should change level",
+			method:         http.MethodPut,
+			body:           `{"level":"debug"}`,
+			expectedStatus: http.StatusOK,
+			expectedLevel:  slog.LevelDebug,
+			expectedBody:   `{"level":"debug"}`,
+		},
+		{
+			name:           "should reject unknown level",
+			method:         http.MethodPut,
+			body:           `{"level":"loud"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLevel:  slog.LevelInfo,
+		},
+		{
+			name:           "should reject invalid body",
+			method:         http.MethodPut,
+			body:           `debug`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLevel:  slog.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			level := new(slog.LevelVar)
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			req := httptest.NewRequest(tt.method, "/admin/loglevel", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			LogLevelHandler(level, logger)(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedLevel, level.Level())
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,39 +1,120 @@
 package k8s
 
 import (
-	"flag"
 	"log/slog"
 	"path/filepath"
+	"time"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
+
+	"k8s-metrics-api/internal/config"
 )
 
 // Client wrap do clientset Kubernetes.
 type Client struct {
+	Name      string
 	Clientset kubernetes.Interface
+	// Dynamic client para recursos customizados.
+	Dynamic dynamic.Interface
+	// PageSize objetos por página nas chamadas List; zero desabilita a paginação.
+	PageSize int64
 }
 
 // NewClient cria client in-cluster ou via kubeconfig.
 func NewClient(logger *slog.Logger) (*Client, error) {
-	config, err := rest.InClusterConfig()
+	return NewClientForCluster(config.ClusterConfig{Name: config.DefaultClusterName}, logger)
+}
+
+// NewClientForCluster cria o client do cluster descrito em cc.
+func NewClientForCluster(cc config.ClusterConfig, logger *slog.Logger) (*Client, error) {
+	restConfig, err := restConfigFor(cc, logger)
 	if err != nil {
-		logger.Debug("Falling back para kubeconfig local", "error", err)
-		var kubeconfig string
-		if home := homedir.HomeDir(); home != "" {
-			kubeconfig = filepath.Join(home, ".kube", "config")
-		}
-		flag.Parse()
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+		return nil, err
+	}
+	cs, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	var pageSize int64
+	if cc.ListPageSize != nil {
+		pageSize = *cc.ListPageSize
+	}
+	return &Client{Name: cc.Name, Clientset: cs, Dynamic: dyn, PageSize: pageSize}, nil
+}
+
+// NewClients cria um client por cluster, na ordem configurada.
+func NewClients(clusters []config.ClusterConfig, logger *slog.Logger) ([]*Client, error) {
+	clients := make([]*Client, 0, len(clusters))
+	for _, cc := range clusters {
+		c, err := NewClientForCluster(cc, logger)
 		if err != nil {
-			return nil, err
+			return nil, &ClusterError{Cluster: cc.Name, Err: err}
 		}
+		clients = append(clients, c)
 	}
-	cs, err := kubernetes.NewForConfig(config)
+	return clients, nil
+}
+
+func restConfigFor(cc config.ClusterConfig, logger *slog.Logger) (*rest.Config, error) {
+	restConfig, err := loadRestConfig(cc, logger)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{Clientset: cs}, nil
+	if cc.QPS > 0 {
+		restConfig.QPS = cc.QPS
+	}
+	if cc.Burst > 0 {
+		restConfig.Burst = cc.Burst
+	}
+	if cc.Timeout > 0 {
+		restConfig.Timeout = time.Duration(cc.Timeout)
+	}
+	if cc.UserAgent != "" {
+		restConfig.UserAgent = cc.UserAgent
+	}
+	return restConfig, nil
+}
+
+func loadRestConfig(cc config.ClusterConfig, logger *slog.Logger) (*rest.Config, error) {
+	if cc.InCluster {
+		return rest.InClusterConfig()
+	}
+	if cc.Kubeconfig == "" && cc.Context == "" {
+		restConfig, err := rest.InClusterConfig()
+		if err == nil {
+			return restConfig, nil
+		}
+		logger.Debug("Falling back para kubeconfig local", "cluster", cc.Name, "error", err)
+	}
+	return kubeconfigLoader(cc).ClientConfig()
+}
+
+// kubeconfigLoader segue as regras do kubectl: sem caminho explícito usa
+// $KUBECONFIG ou ~/.kube/config; vários caminhos são mesclados em ordem.
+func kubeconfigLoader(cc config.ClusterConfig) clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if paths := filepath.SplitList(cc.Kubeconfig); len(paths) == 1 {
+		rules.ExplicitPath = paths[0]
+	} else if len(paths) > 1 {
+		rules.Precedence = paths
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: cc.Context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
 }
+
+// ClusterError associa um erro ao cluster de origem.
+type ClusterError struct {
+	Cluster string
+	Err     error
+}
+
+func (e *ClusterError) Error() string { return "cluster " + e.Cluster + ": " + e.Err.Error() }
+
+func (e *ClusterError) Unwrap() error { return e.Err }
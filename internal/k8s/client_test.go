@@ -3,9 +3,14 @@ package k8s
 import (
 	"log/slog"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s-metrics-api/internal/config"
 )
 
 func TestNewClient(t *testing.T) {
@@ -62,3 +67,119 @@ func TestNewClient(t *testing.T) {
 		t.Run(tt.name, tt.test)
 	}
 }
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: east
+  cluster:
+    server: https://east.example.com:6443
+- name: west
+  cluster:
+    server: https://west.example.com:6443
+users:
+- name: dev
+  user:
+    token: abc
+contexts:
+- name: east
+  context: {cluster: east, user: dev}
+- name: west
+  context: {cluster: west, user: dev}
+current-context: east
+`
+
+func TestNewClientForCluster(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600))
+
+	tests := []struct {
+		name         string
+		cluster      config.ClusterConfig
+		expectedHost string
+		expectError  bool
+	}{
+		{
+			name:         "should use current context of explicit kubeconfig",
+			cluster:      config.ClusterConfig{Name: "a", Kubeconfig: kubeconfig},
+			expectedHost: "https://east.example.com:6443",
+		},
+		{
+			name:         "should honor context override",
+			cluster:      config.ClusterConfig{Name: "b", Kubeconfig: kubeconfig, Context: "west"},
+			expectedHost: "https://west.example.com:6443",
+		},
+		{
+			name:        "should fail on unknown context",
+			cluster:     config.ClusterConfig{Name: "c", Kubeconfig: kubeconfig, Context: "north"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+			// Act
+			restConfig, err := restConfigFor(tt.cluster, logger)
+			client, clientErr := NewClientForCluster(tt.cluster, logger)
+
+			// Assert
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Error(t, clientErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, clientErr)
+			assert.Equal(t, tt.expectedHost, restConfig.Host)
+			assert.Equal(t, tt.cluster.Name, client.Name)
+		})
+	}
+}
+
+func TestNewClientsReportsCluster(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	clusters := []config.ClusterConfig{{Name: "broken", Kubeconfig: filepath.Join(t.TempDir(), "missing")}}
+
+	// Act
+	clients, err := NewClients(clusters, logger)
+
+	// Assert
+	assert.Nil(t, clients)
+	var clusterErr *ClusterError
+	require.ErrorAs(t, err, &clusterErr)
+	assert.Equal(t, "broken", clusterErr.Cluster)
+}
+
+func TestRestConfigForClientSettings(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	require.NoError(t, os.WriteFile(first, []byte("apiVersion: v1\nkind: Config\n"), 0o600))
+	require.NoError(t, os.WriteFile(second, []byte(testKubeconfig), 0o600))
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	cc := config.ClusterConfig{
+		Name:       "kind",
+		Kubeconfig: first + string(filepath.ListSeparator) + second,
+		Context:    "west",
+		QPS:        50,
+		Burst:      100,
+		Timeout:    config.Duration(5 * time.Second),
+		UserAgent:  "k8s-metrics-api/test",
+	}
+
+	// Act
+	restConfig, err := restConfigFor(cc, logger)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://west.example.com:6443", restConfig.Host)
+	assert.Equal(t, float32(50), restConfig.QPS)
+	assert.Equal(t, 100, restConfig.Burst)
+	assert.Equal(t, 5*time.Second, restConfig.Timeout)
+	assert.Equal(t, "k8s-metrics-api/test", restConfig.UserAgent)
+}
@@ -0,0 +1,126 @@
+package k8s
+
+import (
+	"regexp"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"k8s-metrics-api/internal/config"
+)
+
+// NamespaceFilter aplica NamespaceFilterConfig às chamadas List e às
+// agregações. Um filtro nil aceita todos os namespaces e pods.
+type NamespaceFilter struct {
+	include   map[string]bool
+	exclude   map[string]bool
+	includeRe *regexp.Regexp
+	excludeRe *regexp.Regexp
+	nsLabels  labels.Selector
+	podLabels string
+}
+
+// NewNamespaceFilter compila o filtro descrito em cfg.
+func NewNamespaceFilter(cfg config.NamespaceFilterConfig) (*NamespaceFilter, error) {
+	f := &NamespaceFilter{include: set(cfg.Include), exclude: set(cfg.Exclude), podLabels: cfg.PodLabelSelector}
+	var err error
+	if f.includeRe, err = anchored(cfg.IncludeRegex); err != nil {
+		return nil, err
+	}
+	if f.excludeRe, err = anchored(cfg.ExcludeRegex); err != nil {
+		return nil, err
+	}
+	if f.nsLabels, err = labels.Parse(cfg.LabelSelector); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Match indica se o namespace deve ser coletado.
+func (f *NamespaceFilter) Match(name string, nsLabels map[string]string) bool {
+	if f == nil {
+		return true
+	}
+	if f.exclude[name] || (f.excludeRe != nil && f.excludeRe.MatchString(name)) {
+		return false
+	}
+	if (len(f.include) > 0 || f.includeRe != nil) && !f.include[name] && (f.includeRe == nil || !f.includeRe.MatchString(name)) {
+		return false
+	}
+	return f.nsLabels.Matches(labels.Set(nsLabels))
+}
+
+// HasLabelSelector indica se o filtro depende dos labels dos namespaces.
+func (f *NamespaceFilter) HasLabelSelector() bool { return f != nil && !f.nsLabels.Empty() }
+
+// NamespaceListOptions opções do List de namespaces, com o label selector.
+func (f *NamespaceFilter) NamespaceListOptions() metav1.ListOptions {
+	if f == nil {
+		return metav1.ListOptions{}
+	}
+	return metav1.ListOptions{LabelSelector: f.nsLabels.String()}
+}
+
+// Scopes retorna os namespaces a consultar nos List de recursos namespaced.
+// Com apenas uma lista de inclusão, consulta cada namespace; caso contrário,
+// o cluster inteiro ("").
+func (f *NamespaceFilter) Scopes() []string {
+	if f == nil || len(f.include) == 0 || f.includeRe != nil {
+		return []string{metav1.NamespaceAll}
+	}
+	scopes := make([]string, 0, len(f.include))
+	for _, ns := range sortedKeys(f.include) {
+		if !f.exclude[ns] {
+			scopes = append(scopes, ns)
+		}
+	}
+	return scopes
+}
+
+// ListOptions opções dos List de recursos namespaced: namespaces excluídos
+// explicitamente são descartados pelo API server via field selector.
+func (f *NamespaceFilter) ListOptions() metav1.ListOptions {
+	if f == nil || len(f.exclude) == 0 {
+		return metav1.ListOptions{}
+	}
+	sels := make([]fields.Selector, 0, len(f.exclude))
+	for _, ns := range sortedKeys(f.exclude) {
+		sels = append(sels, fields.OneTermNotEqualSelector("metadata.namespace", ns))
+	}
+	return metav1.ListOptions{FieldSelector: fields.AndSelectors(sels...).String()}
+}
+
+// PodListOptions como ListOptions, acrescidas do label selector de pods.
+func (f *NamespaceFilter) PodListOptions() metav1.ListOptions {
+	opts := f.ListOptions()
+	if f != nil {
+		opts.LabelSelector = f.podLabels
+	}
+	return opts
+}
+
+func set(items []string) map[string]bool {
+	m := make(map[string]bool, len(items))
+	for _, i := range items {
+		m[i] = true
+	}
+	return m
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func anchored(re string) (*regexp.Regexp, error) {
+	if re == "" {
+		return nil, nil
+	}
+	return regexp.Compile("^(?:" + re + ")$")
+}
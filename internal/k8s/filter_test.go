@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s-metrics-api/internal/config"
+)
+
+func TestNamespaceFilterMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.NamespaceFilterConfig
+		ns       string
+		labels   map[string]string
+		expected bool
+	}{
+		{name: "should accept everything without rules", ns: "any", expected: true},
+		{name: "should accept included namespace", cfg: config.NamespaceFilterConfig{Include: []string{"prod"}}, ns: "prod", expected: true},
+		{name: "should reject namespace outside include list", cfg: config.NamespaceFilterConfig{Include: []string{"prod"}}, ns: "dev", expected: false},
+		{name: "should accept namespace matching include regex", cfg: config.NamespaceFilterConfig{Include: []string{"prod"}, IncludeRegex: "team-.*"}, ns: "team-a", expected: true},
+		{name: "should match regex against the whole name", cfg: config.NamespaceFilterConfig{ExcludeRegex: "ci"}, ns: "ci-123", expected: true},
+		{name: "should reject namespace matching exclude regex", cfg: config.NamespaceFilterConfig{ExcludeRegex: "ci-[0-9]+"}, ns: "ci-123", expected: false},
+		{name: "should let exclude win over include", cfg: config.NamespaceFilterConfig{Include: []string{"prod"}, Exclude: []string{"prod"}}, ns: "prod", expected: false},
+		{name: "should require label selector", cfg: config.NamespaceFilterConfig{LabelSelector: "team=platform"}, ns: "prod", labels: map[string]string{"team": "data"}, expected: false},
+		{name: "should accept matching labels", cfg: config.NamespaceFilterConfig{LabelSelector: "team in (platform,data)"}, ns: "prod", labels: map[string]string{"team": "data"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			f, err := NewNamespaceFilter(tt.cfg)
+			require.NoError(t, err)
+
+			// Act
+			got := f.Match(tt.ns, tt.labels)
+
+			// Assert
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestNamespaceFilterListOptions(t *testing.T) {
+	// Arrange
+	f, err := NewNamespaceFilter(config.NamespaceFilterConfig{
+		Include:          []string{"prod", "staging", "ci"},
+		Exclude:          []string{"ci", "kube-system"},
+		LabelSelector:    "team=platform",
+		PodLabelSelector: "app=web",
+	})
+	require.NoError(t, err)
+
+	// Act
+	scopes := f.Scopes()
+	nsOpts := f.NamespaceListOptions()
+	podOpts := f.PodListOptions()
+
+	// Assert
+	assert.Equal(t, []string{"prod", "staging"}, scopes)
+	assert.Equal(t, "team=platform", nsOpts.LabelSelector)
+	assert.Equal(t, "metadata.namespace!=ci,metadata.namespace!=kube-system", podOpts.FieldSelector)
+	assert.Equal(t, "app=web", podOpts.LabelSelector)
+}
+
+func TestNilNamespaceFilter(t *testing.T) {
+	// Arrange
+	var f *NamespaceFilter
+
+	// Act & Assert
+	assert.True(t, f.Match("any", nil))
+	assert.Equal(t, []string{metav1.NamespaceAll}, f.Scopes())
+	assert.Equal(t, metav1.ListOptions{}, f.PodListOptions())
+}
@@ -0,0 +1,61 @@
+package k8s
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Workload controlador de mais alto nível de um pod.
+type Workload struct {
+	Kind string
+	Name string
+}
+
+// KindPod tipo usado para pods sem controlador.
+const KindPod = "Pod"
+
+// OwnerResolver resolve o workload de pods a partir das owner references,
+// seguindo ReplicaSet -> Deployment e Job -> CronJob.
+type OwnerResolver struct {
+	owners map[string]*metav1.OwnerReference
+}
+
+// NewOwnerResolver indexa os controladores dos ReplicaSets e Jobs informados.
+func NewOwnerResolver(replicaSets []appsv1.ReplicaSet, jobs []batchv1.Job) *OwnerResolver {
+	r := &OwnerResolver{owners: map[string]*metav1.OwnerReference{}}
+	for _, rs := range replicaSets {
+		r.owners[ownerKey("ReplicaSet", rs.Namespace, rs.Name)] = metav1.GetControllerOf(&rs)
+	}
+	for _, j := range jobs {
+		r.owners[ownerKey("Job", j.Namespace, j.Name)] = metav1.GetControllerOf(&j)
+	}
+	return r
+}
+
+// Resolve retorna o workload do pod. Pods sem controlador são o próprio
+// workload; ReplicaSets e Jobs desconhecidos ou sem dono são mantidos.
+func (r *OwnerResolver) Resolve(p *corev1.Pod) Workload {
+	return r.Parent(p.Namespace, Controller(p))
+}
+
+// Parent retorna o controlador de w no namespace, ou o próprio w se não houver.
+func (r *OwnerResolver) Parent(namespace string, w Workload) Workload {
+	if parent := r.owners[ownerKey(w.Kind, namespace, w.Name)]; parent != nil {
+		return Workload{Kind: parent.Kind, Name: parent.Name}
+	}
+	return w
+}
+
+// Controller retorna o controlador direto do pod, ou o próprio pod se não houver.
+func Controller(p *corev1.Pod) Workload {
+	if ref := metav1.GetControllerOf(p); ref != nil {
+		return Workload{Kind: ref.Kind, Name: ref.Name}
+	}
+	return Workload{Kind: KindPod, Name: p.Name}
+}
+
+func ownerKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
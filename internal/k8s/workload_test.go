@@ -0,0 +1,53 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func controlledBy(kind, name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{*metav1.NewControllerRef(&metav1.ObjectMeta{Name: name}, appsv1.SchemeGroupVersion.WithKind(kind))}
+}
+
+func TestOwnerResolverResolve(t *testing.T) {
+	resolver := NewOwnerResolver(
+		[]appsv1.ReplicaSet{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-7d9f", Namespace: "prod", OwnerReferences: controlledBy("Deployment", "web")}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "bare-rs", Namespace: "prod"}},
+		},
+		[]batchv1.Job{
+			{ObjectMeta: metav1.ObjectMeta{Name: "backup-2890", Namespace: "prod", OwnerReferences: controlledBy("CronJob", "backup")}},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		owners   []metav1.OwnerReference
+		expected Workload
+	}{
+		{name: "should follow replicaset to deployment", owners: controlledBy("ReplicaSet", "web-7d9f"), expected: Workload{Kind: "Deployment", Name: "web"}},
+		{name: "should keep replicaset without owner", owners: controlledBy("ReplicaSet", "bare-rs"), expected: Workload{Kind: "ReplicaSet", Name: "bare-rs"}},
+		{name: "should follow job to cronjob", owners: controlledBy("Job", "backup-2890"), expected: Workload{Kind: "CronJob", Name: "backup"}},
+		{name: "should keep statefulset", owners: controlledBy("StatefulSet", "db"), expected: Workload{Kind: "StatefulSet", Name: "db"}},
+		{name: "should keep unknown replicaset", owners: controlledBy("ReplicaSet", "other"), expected: Workload{Kind: "ReplicaSet", Name: "other"}},
+		{name: "should use the pod itself without controller", expected: Workload{Kind: KindPod, Name: "pod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "prod", OwnerReferences: tt.owners}}
+
+			// Act
+			got := resolver.Resolve(pod)
+
+			// Assert
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name             string
+		format           string
+		ctx              context.Context
+		expectedContains []string
+	}{
+		{
+			name:             "should write json with request id",
+			format:           FormatJSON,
+			ctx:              WithRequestID(context.Background(), "req-1"),
+			expectedContains: []string{`"msg":"hello"`, `"requestId":"req-1"`, `"cluster":"a"`},
+		},
+		{
+			name:             "should write text with request id",
+			format:           FormatText,
+			ctx:              WithRequestID(context.Background(), "req-2"),
+			expectedContains: []string{"msg=hello", "requestId=req-2", "cluster=a"},
+		},
+		{
+			name:             "should omit request id without context value",
+			format:           FormatJSON,
+			ctx:              context.Background(),
+			expectedContains: []string{`"msg":"hello"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			logger := New(&buf, tt.format, slog.LevelInfo).With("cluster", "a")
+
+			// Act
+			logger.InfoContext(tt.ctx, "hello")
+
+			// Assert
+			for _, want := range tt.expectedContains {
+				assert.Contains(t, buf.String(), want)
+			}
+			if RequestID(tt.ctx) == "" {
+				assert.NotContains(t, buf.String(), "requestId")
+			}
+		})
+	}
+}
+
+func TestNewHonorsLevelVar(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	logger := New(&buf, FormatJSON, level)
+
+	// Act
+	logger.Debug("hidden")
+	level.Set(slog.LevelDebug)
+	logger.Debug("shown")
+
+	// Assert
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "shown", entry["msg"])
+}
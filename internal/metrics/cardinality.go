@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s-metrics-api/internal/config"
+)
+
+// OverflowValue valor dos labels da série que recebe tudo o que excede o
+// limite de séries de uma família.
+const OverflowValue = "__overflow__"
+
+// families labels de cada família com label variável, na ordem usada por
+// WithLabelValues.
+var families = map[string][]string{
+	"k8s_node_status_ready":                {"node"},
+	"k8s_pod_status_phase":                 {"namespace", "phase"},
+	"k8s_deployment_replicas_desired":      {"namespace", "deployment"},
+	"k8s_deployment_replicas_available":    {"namespace", "deployment"},
+	"k8s_container_restarts_total":         {"namespace", "pod", "container"},
+	"k8s_node_cpu_allocatable_cores":       {"node"},
+	"k8s_node_memory_allocatable_bytes":    {"node"},
+	"k8s_namespace_cpu_requests_cores":     {"namespace"},
+	"k8s_namespace_memory_requests_bytes":  {"namespace"},
+	"k8s_namespace_cpu_limits_cores":       {"namespace"},
+	"k8s_namespace_memory_limits_bytes":    {"namespace"},
+	"k8s_workload_pods":                    {"namespace", "workload_kind", "workload", "phase"},
+	"k8s_workload_pods_ready":              {"namespace", "workload_kind", "workload"},
+	"k8s_workload_restarts_total":          {"namespace", "workload_kind", "workload"},
+	"k8s_workload_cpu_requests_cores":      {"namespace", "workload_kind", "workload"},
+	"k8s_workload_memory_requests_bytes":   {"namespace", "workload_kind", "workload"},
+	"k8s_workload_cpu_limits_cores":        {"namespace", "workload_kind", "workload"},
+	"k8s_workload_memory_limits_bytes":     {"namespace", "workload_kind", "workload"},
+	"k8s_pods_by_qos_class":                {"namespace", "qos_class"},
+	"k8s_pods_by_priority_class":           {"namespace", "priority_class"},
+	"k8s_node_pods_by_qos_class":           {"node", "qos_class"},
+	"k8s_node_pods_by_priority_class":      {"node", "priority_class"},
+	"k8s_image_policy_violations":          {"namespace", "reason"},
+	"k8s_pod_compliance_violations":        {"namespace", "rule"},
+	"k8s_deployment_compliance_violations": {"namespace", "rule"},
+	"k8s_services_by_type":                 {"namespace", "type"},
+	"k8s_service_loadbalancer_pending":     {"namespace", "service"},
+	"k8s_service_endpoints_ready":          {"namespace", "service"},
+	"k8s_ingress_host_info":                {"namespace", "ingress", "host", "tls"},
+	"k8s_ingress_backend_missing":          {"namespace", "ingress", "service"},
+}
+
+// ValidateCardinality verifica se famílias e labels citados em c existem.
+func ValidateCardinality(c config.CardinalityConfig) error {
+	var problems []string
+	for family, drop := range c.DropLabels {
+		labels, ok := families[family]
+		if !ok {
+			problems = append(problems, "família desconhecida: "+family)
+			continue
+		}
+		for _, l := range drop {
+			if !contains(labels, l) {
+				problems = append(problems, fmt.Sprintf("%s não possui o label %s", family, l))
+			}
+		}
+	}
+	for family := range c.MaxSeries {
+		if _, ok := families[family]; !ok {
+			problems = append(problems, "família desconhecida: "+family)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("limites de cardinalidade inválidos: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// GaugeVec é um prometheus.GaugeVec com controles de cardinalidade. Os labels
+// descartados deixam de existir na série, então valores que passam a cair na
+// mesma série devem ser acumulados com Add (após Reset). Acima de maxSeries,
+// novas séries são redirecionadas para a série de overflow.
+type GaugeVec struct {
+	family    string
+	vec       *prometheus.GaugeVec
+	keep      []int
+	maxSeries int
+	emitted   prometheus.Gauge
+
+	mu       sync.Mutex
+	seen     map[string]bool
+	overflow bool
+}
+
+func newGaugeVec(name, help string, lim config.CardinalityConfig, emitted *prometheus.GaugeVec) *GaugeVec {
+	all := families[name]
+	drop, maxSeries := lim.For(name)
+	g := &GaugeVec{family: name, maxSeries: maxSeries, emitted: emitted.WithLabelValues(name), seen: map[string]bool{}}
+	var kept []string
+	for i, l := range all {
+		if !contains(drop, l) {
+			g.keep = append(g.keep, i)
+			kept = append(kept, l)
+		}
+	}
+	g.vec = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, kept)
+	return g
+}
+
+// WithLabelValues retorna a série correspondente aos valores de todos os
+// labels da família, já com os labels descartados removidos.
+func (g *GaugeVec) WithLabelValues(lvs ...string) prometheus.Gauge {
+	kept := make([]string, len(g.keep))
+	for i, idx := range g.keep {
+		kept[i] = lvs[idx]
+	}
+	key := strings.Join(kept, "\xff")
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.seen[key] {
+		if g.maxSeries > 0 && len(g.seen) >= g.maxSeries {
+			for i := range kept {
+				kept[i] = OverflowValue
+			}
+			if !g.overflow {
+				g.overflow = true
+				g.emitted.Inc()
+			}
+			return g.vec.WithLabelValues(kept...)
+		}
+		g.seen[key] = true
+		g.emitted.Inc()
+	}
+	return g.vec.WithLabelValues(kept...)
+}
+
+// Reset remove todas as séries.
+func (g *GaugeVec) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.vec.Reset()
+	g.seen = map[string]bool{}
+	g.overflow = false
+	g.emitted.Set(0)
+}
+
+// Describe implementa prometheus.Collector.
+func (g *GaugeVec) Describe(ch chan<- *prometheus.Desc) { g.vec.Describe(ch) }
+
+// Collect implementa prometheus.Collector.
+func (g *GaugeVec) Collect(ch chan<- prometheus.Metric) { g.vec.Collect(ch) }
+
+func contains(list []string, v string) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s-metrics-api/internal/config"
+)
+
+func TestGaugeVecCardinality(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             config.CardinalityConfig
+		expected        string
+		expectedEmitted float64
+	}{
+		{
+			name: "should keep all series without limits",
+			expected: `
+k8s_container_restarts_total{cluster="c",container="app",namespace="a",pod="p1"} 1
+k8s_container_restarts_total{cluster="c",container="app",namespace="a",pod="p2"} 2
+k8s_container_restarts_total{cluster="c",container="app",namespace="b",pod="p3"} 4
+`,
+			expectedEmitted: 3,
+		},
+		{
+			name: "should aggregate restarts per namespace when dropping labels",
+			cfg:  config.CardinalityConfig{DropLabels: map[string][]string{"k8s_container_restarts_total": {"pod", "container"}}},
+			expected: `
+k8s_container_restarts_total{cluster="c",namespace="a"} 3
+k8s_container_restarts_total{cluster="c",namespace="b"} 4
+`,
+			expectedEmitted: 2,
+		},
+		{
+			name: "should send series above the limit to the overflow bucket",
+			cfg:  config.CardinalityConfig{DefaultMaxSeries: 1, MaxSeries: map[string]int{"k8s_node_status_ready": 5}},
+			expected: `
+k8s_container_restarts_total{cluster="c",container="__overflow__",namespace="__overflow__",pod="__overflow__"} 6
+k8s_container_restarts_total{cluster="c",container="app",namespace="a",pod="p1"} 1
+`,
+			expectedEmitted: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			reg := prometheus.NewRegistry()
+			m := NewClusterMetrics(reg, "c", logger, WithCardinality(tt.cfg))
+			m.ContainerRestarts.WithLabelValues("a", "p0", "old").Add(9)
+			m.ContainerRestarts.Reset()
+
+			// Act
+			m.ContainerRestarts.WithLabelValues("a", "p1", "app").Add(1)
+			m.ContainerRestarts.WithLabelValues("a", "p2", "app").Add(2)
+			m.ContainerRestarts.WithLabelValues("b", "p3", "app").Add(4)
+
+			// Assert
+			header := "# HELP k8s_container_restarts_total Restart count\n# TYPE k8s_container_restarts_total gauge"
+			require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(header+tt.expected), "k8s_container_restarts_total"))
+			assert.Equal(t, tt.expectedEmitted, testutil.ToFloat64(m.Series.WithLabelValues("k8s_container_restarts_total")))
+		})
+	}
+}
+
+func TestValidateCardinality(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.CardinalityConfig
+		expectError string
+	}{
+		{
+			name: "should accept known families and labels",
+			cfg: config.CardinalityConfig{
+				DropLabels: map[string][]string{"k8s_pod_status_phase": {"phase"}},
+				MaxSeries:  map[string]int{"k8s_node_status_ready": 100},
+			},
+		},
+		{
+			name:        "should reject unknown family",
+			cfg:         config.CardinalityConfig{MaxSeries: map[string]int{"k8s_nope": 1}},
+			expectError: "família desconhecida: k8s_nope",
+		},
+		{
+			name:        "should reject certificate expiry family",
+			cfg:         config.CardinalityConfig{MaxSeries: map[string]int{"k8s_tls_secret_expiry_timestamp_seconds": 10}},
+			expectError: "família desconhecida: k8s_tls_secret_expiry_timestamp_seconds",
+		},
+		{
+			name:        "should reject unknown label",
+			cfg:         config.CardinalityConfig{DropLabels: map[string][]string{"k8s_node_status_ready": {"zone"}}},
+			expectError: "k8s_node_status_ready não possui o label zone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := ValidateCardinality(tt.cfg)
+
+			// Assert
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Origens de Certificate.
+const (
+	CertificateKindSecret      = "Secret"
+	CertificateKindCertManager = "Certificate"
+)
+
+// Certificate campos de um certificado TLS expostos na resposta JSON. Apenas
+// metadados e campos do certificado são guardados; o conteúdo dos Secrets
+// (inclusive a chave privada) é descartado logo após a leitura.
+type Certificate struct {
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace"`
+	// Kind Secret (kubernetes.io/tls) ou Certificate (cert-manager).
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Secret Secret de destino de um Certificate do cert-manager.
+	Secret    string    `json:"secret,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	Issuer    string    `json:"issuer,omitempty"`
+	DNSNames  []string  `json:"dnsNames,omitempty"`
+	NotBefore time.Time `json:"notBefore,omitzero"`
+	NotAfter  time.Time `json:"notAfter"`
+}
+
+// ExpiresWithin indica se o certificado expira antes de now+window.
+func (c Certificate) ExpiresWithin(now time.Time, window time.Duration) bool {
+	return c.NotAfter.Before(now.Add(window))
+}
+
+var errNoCertificate = errors.New("nenhum certificado PEM em tls.crt")
+
+// parseCertificate lê o primeiro certificado (o da folha) de um PEM.
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errNoCertificate
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// TLSSecretCollector validade dos certificados em Secrets kubernetes.io/tls.
+type TLSSecretCollector struct{}
+
+func (TLSSecretCollector) Name() string { return "tlssecrets" }
+
+func (TLSSecretCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{listRule("", "secrets")}
+}
+
+func (TLSSecretCollector) Describe() []string {
+	return []string{"k8s_tls_secret_expiry_timestamp_seconds"}
+}
+
+// Collect lista apenas Secrets do tipo kubernetes.io/tls e converte cada um
+// em Certificate durante a paginação, sem reter os Secrets.
+func (c TLSSecretCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	opts := src.Filter.ListOptions()
+	sel := fields.OneTermEqualSelector("type", string(corev1.SecretTypeTLS)).String()
+	if opts.FieldSelector != "" {
+		sel = opts.FieldSelector + "," + sel
+	}
+	opts.FieldSelector = sel
+	var res certificateResult
+	err := src.Each(ctx, c.Name(), src.Filter.Scopes(), opts, func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return src.Client.Clientset.CoreV1().Secrets(ns).List(ctx, o)
+	}, func(obj runtime.Object) {
+		secret := obj.(*corev1.Secret)
+		if secret.Type != corev1.SecretTypeTLS {
+			return
+		}
+		cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			src.Log.DebugContext(ctx, "Certificado inválido em Secret TLS", "namespace", secret.Namespace, "secret", secret.Name, "error", err)
+			return
+		}
+		res = append(res, Certificate{
+			Namespace: secret.Namespace,
+			Kind:      CertificateKindSecret,
+			Name:      secret.Name,
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			DNSNames:  cert.DNSNames,
+			NotBefore: cert.NotBefore.UTC(),
+			NotAfter:  cert.NotAfter.UTC(),
+		})
+	})
+	return res, err
+}
+
+type certificateResult []Certificate
+
+func (r certificateResult) Apply(s *Snapshot, m *PrometheusMetrics) {
+	m.TLSSecretExpiry.Reset()
+	for _, cert := range r {
+		if !s.Selected(cert.Namespace) {
+			continue
+		}
+		m.TLSSecretExpiry.WithLabelValues(cert.Namespace, cert.Name, cert.Subject).Set(float64(cert.NotAfter.Unix()))
+		if s.Allowed(cert.Namespace) {
+			s.Certificates = append(s.Certificates, cert)
+		}
+	}
+}
+
+var certManagerGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// CertManagerCollector validade dos Certificates do cert-manager, pelo
+// status.notAfter. Não faz parte do DefaultRegistry: exige o CRD instalado.
+type CertManagerCollector struct{}
+
+func (CertManagerCollector) Name() string { return "certmanager" }
+
+func (CertManagerCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{listRule(certManagerGVR.Group, certManagerGVR.Resource)}
+}
+
+func (CertManagerCollector) Describe() []string {
+	return []string{"k8s_certmanager_certificate_expiry_timestamp_seconds"}
+}
+
+// Collect ignora Certificates ainda não emitidos (sem status.notAfter).
+func (c CertManagerCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	var res certManagerResult
+	err := src.Each(ctx, c.Name(), src.Filter.Scopes(), src.Filter.ListOptions(), func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return src.Client.Dynamic.Resource(certManagerGVR).Namespace(ns).List(ctx, o)
+	}, func(obj runtime.Object) {
+		u := obj.(*unstructured.Unstructured)
+		notAfter, _, _ := unstructured.NestedString(u.Object, "status", "notAfter")
+		t, err := time.Parse(time.RFC3339, notAfter)
+		if err != nil {
+			return
+		}
+		cert := Certificate{Namespace: u.GetNamespace(), Kind: CertificateKindCertManager, Name: u.GetName(), NotAfter: t.UTC()}
+		cert.Secret, _, _ = unstructured.NestedString(u.Object, "spec", "secretName")
+		cert.DNSNames, _, _ = unstructured.NestedStringSlice(u.Object, "spec", "dnsNames")
+		if cn, _, _ := unstructured.NestedString(u.Object, "spec", "commonName"); cn != "" {
+			cert.Subject = "CN=" + cn
+		}
+		if nb, _, _ := unstructured.NestedString(u.Object, "status", "notBefore"); nb != "" {
+			if t, err := time.Parse(time.RFC3339, nb); err == nil {
+				cert.NotBefore = t.UTC()
+			}
+		}
+		res = append(res, cert)
+	})
+	return res, err
+}
+
+type certManagerResult []Certificate
+
+func (r certManagerResult) Apply(s *Snapshot, m *PrometheusMetrics) {
+	m.CertManagerExpiry.Reset()
+	for _, cert := range r {
+		if !s.Selected(cert.Namespace) {
+			continue
+		}
+		m.CertManagerExpiry.WithLabelValues(cert.Namespace, cert.Name).Set(float64(cert.NotAfter.Unix()))
+		if s.Allowed(cert.Namespace) {
+			s.Certificates = append(s.Certificates, cert)
+		}
+	}
+}
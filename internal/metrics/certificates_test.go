@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s-metrics-api/internal/k8s"
+)
+
+// selfSigned gera um certificado autoassinado em PEM, seguido da chave.
+func selfSigned(t *testing.T, cn string, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})...)
+}
+
+func tlsSecret(ns, name string, crt []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: crt, corev1.TLSPrivateKeyKey: []byte("chave")},
+	}
+}
+
+func TestTLSSecretCollector(t *testing.T) {
+	// Arrange
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	clientset := fake.NewSimpleClientset(
+		tlsSecret("shop", "checkout-tls", selfSigned(t, "checkout.example.com", expiry)),
+		tlsSecret("ci", "runner-tls", selfSigned(t, "runner.example.com", expiry.Add(time.Hour))),
+		tlsSecret("shop", "broken-tls", []byte("não é PEM")),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "db"}, Data: map[string][]byte{"password": []byte("x")}},
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	src := &Source{Client: &k8s.Client{Clientset: clientset}, Metrics: m.Collector, Log: logger}
+	snap := NewSnapshot(nil, func(ns string) bool { return ns == "shop" })
+
+	// Act
+	res, err := TLSSecretCollector{}.Collect(context.Background(), src)
+	require.NoError(t, err)
+	res.Apply(snap, m)
+
+	// Assert
+	assert.Equal(t, 2, testutil.CollectAndCount(m.TLSSecretExpiry, "k8s_tls_secret_expiry_timestamp_seconds"))
+	assert.Equal(t, float64(expiry.Unix()), testutil.ToFloat64(m.TLSSecretExpiry.WithLabelValues("shop", "checkout-tls", "CN=checkout.example.com")))
+	assert.Equal(t, []Certificate{{
+		Namespace: "shop",
+		Kind:      CertificateKindSecret,
+		Name:      "checkout-tls",
+		Subject:   "CN=checkout.example.com",
+		Issuer:    "CN=checkout.example.com",
+		DNSNames:  []string{"checkout.example.com"},
+		NotBefore: expiry.Add(-90 * 24 * time.Hour),
+		NotAfter:  expiry,
+	}}, snap.Certificates)
+}
+
+func TestCertManagerCollector(t *testing.T) {
+	// Arrange
+	certificate := func(ns, name, notAfter string) *unstructured.Unstructured {
+		obj := map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]any{"namespace": ns, "name": name},
+			"spec":       map[string]any{"secretName": name + "-tls", "commonName": name + ".example.com"},
+		}
+		if notAfter != "" {
+			obj["status"] = map[string]any{"notAfter": notAfter}
+		}
+		return &unstructured.Unstructured{Object: obj}
+	}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{certManagerGVR: "CertificateList"},
+		certificate("shop", "checkout", "2030-01-02T03:04:05Z"),
+		certificate("shop", "pending", ""),
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	src := &Source{Client: &k8s.Client{Dynamic: dyn}, Metrics: m.Collector, Log: logger}
+	snap := NewSnapshot(nil, nil)
+
+	// Act
+	res, err := CertManagerCollector{}.Collect(context.Background(), src)
+	require.NoError(t, err)
+	res.Apply(snap, m)
+
+	// Assert
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.CertManagerExpiry))
+	assert.Equal(t, float64(expiry.Unix()), testutil.ToFloat64(m.CertManagerExpiry.WithLabelValues("shop", "checkout")))
+	assert.Equal(t, []Certificate{{
+		Namespace: "shop",
+		Kind:      CertificateKindCertManager,
+		Name:      "checkout",
+		Secret:    "checkout-tls",
+		Subject:   "CN=checkout.example.com",
+		NotAfter:  expiry,
+	}}, snap.Certificates)
+}
+
+func TestCertificateExpiresWithin(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		expected bool
+	}{
+		{name: "should include expired", notAfter: now.Add(-time.Hour), expected: true},
+		{name: "should include inside window", notAfter: now.Add(24 * time.Hour), expected: true},
+		{name: "should exclude outside window", notAfter: now.Add(60 * 24 * time.Hour), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c := Certificate{NotAfter: tt.notAfter}
+
+			// Act
+			got := c.ExpiresWithin(now, 30*24*time.Hour)
+
+			// Assert
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
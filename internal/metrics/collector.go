@@ -0,0 +1,278 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/pager"
+
+	"k8s-metrics-api/internal/k8s"
+)
+
+// Collector coleta um tipo de recurso do cluster. Collect apenas lista os
+// objetos; o Result é aplicado ao Snapshot e às métricas depois que todas
+// as listagens da coleta terminam, na ordem do Registry.
+type Collector interface {
+	// Name nome do recurso, usado em sections, errors e na configuração.
+	Name() string
+	// Rules permissões RBAC necessárias para Collect.
+	Rules() []rbacv1.PolicyRule
+	// Describe famílias de métricas preenchidas pelo Result.
+	Describe() []string
+	// Collect lista os objetos do cluster.
+	Collect(ctx context.Context, src *Source) (Result, error)
+}
+
+// Result resultado de uma listagem. Apply deve substituir por completo as
+// séries do collector, pois o último Result é reaplicado quando a listagem
+// seguinte falha.
+type Result interface {
+	Apply(s *Snapshot, m *PrometheusMetrics)
+}
+
+// Registry collectors disponíveis, na ordem em que os resultados são aplicados.
+type Registry struct {
+	collectors []Collector
+}
+
+// NewRegistry cria Registry com os collectors informados.
+func NewRegistry(collectors ...Collector) *Registry {
+	r := &Registry{}
+	for _, c := range collectors {
+		r.Register(c)
+	}
+	return r
+}
+
+// DefaultRegistry collectors embutidos. Namespaces vêm primeiro: o filtro de
+// namespaces aplicado por eles vale para os demais. Ingresses vêm depois de
+// services, contra os quais os backends são conferidos.
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		NamespaceCollector{}, NodeCollector{}, PodCollector{}, DeploymentCollector{},
+		ServiceCollector{}, IngressCollector{}, TLSSecretCollector{},
+	)
+}
+
+// Register acrescenta c ao final do Registry. Um nome repetido substitui o
+// collector anterior na mesma posição.
+func (r *Registry) Register(c Collector) {
+	for i, cur := range r.collectors {
+		if cur.Name() == c.Name() {
+			r.collectors[i] = c
+			return
+		}
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+// Names nomes dos collectors registrados.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// Select retorna os collectors em enabled (vazio: todos) que não estão em
+// disabled. Nomes desconhecidos são rejeitados.
+func (r *Registry) Select(enabled, disabled []string) ([]Collector, error) {
+	known := map[string]bool{}
+	for _, c := range r.collectors {
+		known[c.Name()] = true
+	}
+	for _, name := range append(append([]string{}, enabled...), disabled...) {
+		if !known[name] {
+			return nil, fmt.Errorf("collector desconhecido: %s (disponíveis: %v)", name, r.Names())
+		}
+	}
+	var out []Collector
+	for _, c := range r.collectors {
+		if (len(enabled) == 0 || contains(enabled, c.Name())) && !contains(disabled, c.Name()) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// Source acesso de um collector ao cluster.
+type Source struct {
+	Client *k8s.Client
+	// Filter filtro de namespaces; nil aceita todos.
+	Filter *k8s.NamespaceFilter
+	// Images política de imagens; nil não conta violações.
+	Images *ImagePolicy
+	// Compliance regras de conformidade; nil não avalia nenhuma.
+	Compliance *ComplianceRules
+	Metrics    CollectorMetrics
+	Log        *slog.Logger
+}
+
+// ListFunc lista uma página de um escopo ("" para o cluster inteiro).
+type ListFunc func(ctx context.Context, ns string, opts metav1.ListOptions) (runtime.Object, error)
+
+// ClusterScope escopo dos recursos não namespaced.
+var ClusterScope = []string{metav1.NamespaceAll}
+
+// Each percorre os objetos de resource em cada escopo, página a página
+// (Limit/Continue, com o PageSize do client), e registra duração, erros e
+// objetos processados.
+func (s *Source) Each(ctx context.Context, resource string, scopes []string, opts metav1.ListOptions, list ListFunc, fn func(runtime.Object)) error {
+	start := time.Now()
+	n := 0
+	for _, ns := range scopes {
+		p := pager.New(func(ctx context.Context, o metav1.ListOptions) (runtime.Object, error) {
+			return list(ctx, ns, o)
+		})
+		p.PageSize = s.Client.PageSize
+		// Apenas uma página à frente da que está sendo processada.
+		p.PageBufferSize = 1
+		err := p.EachListItem(ctx, opts, func(obj runtime.Object) error {
+			fn(obj)
+			n++
+			return nil
+		})
+		if err != nil {
+			s.Metrics.ObserveList(resource, start, 0, err)
+			return err
+		}
+	}
+	s.Metrics.ObserveList(resource, start, n, nil)
+	return nil
+}
+
+// List como Each, acumulando os objetos.
+func List[T any, PT interface {
+	*T
+	runtime.Object
+}](ctx context.Context, s *Source, resource string, scopes []string, opts metav1.ListOptions, list ListFunc) ([]T, error) {
+	var items []T
+	err := s.Each(ctx, resource, scopes, opts, list, func(obj runtime.Object) {
+		items = append(items, *obj.(PT))
+	})
+	return items, err
+}
+
+// Snapshot visão JSON de uma coleta de cluster, preenchida pelos Results.
+// As métricas Prometheus consideram todos os namespaces selecionados; o
+// Snapshot, apenas os permitidos à identidade autenticada.
+type Snapshot struct {
+	NodeCount       int            `json:"nodeCount"`
+	PodCount        int            `json:"podCount"`
+	DeploymentCount int            `json:"deploymentCount"`
+	ServiceCount    int            `json:"serviceCount"`
+	NamespaceCount  int            `json:"namespaceCount"`
+	PodPhases       map[string]int `json:"podPhases"`
+	// Pods não concluídos por classe de QoS e por PriorityClass ("none" sem
+	// classe), no total, por namespace e por nó.
+	PodQOSClasses       map[string]int        `json:"podQosClasses,omitempty"`
+	PodPriorityClasses  map[string]int        `json:"podPriorityClasses,omitempty"`
+	NamespacePodClasses map[string]PodClasses `json:"namespacePodClasses,omitempty"`
+	NodePodClasses      map[string]PodClasses `json:"nodePodClasses,omitempty"`
+	// Exposição: services por tipo, LoadBalancers sem IP externo e services
+	// sem endpoints prontos (por "namespace/nome"), e inventário de Ingresses.
+	ServicesByType           map[string]int `json:"servicesByType,omitempty"`
+	PendingLoadBalancers     []string       `json:"pendingLoadBalancers,omitempty"`
+	ServicesWithoutEndpoints []string       `json:"servicesWithoutEndpoints,omitempty"`
+	Ingresses                []Ingress      `json:"ingresses,omitempty"`
+	// Images imagens em execução, ImageRegistries pods distintos por
+	// registry e ImageViolations pods fora da política por motivo; expostos
+	// em /metrics/images.
+	Images          []Image        `json:"-"`
+	ImageRegistries map[string]int `json:"-"`
+	ImageViolations map[string]int `json:"-"`
+	// Labels e annotations permitidos em METRICS_*_ALLOWLIST, por objeto.
+	// Workloads são indexados por "namespace/nome".
+	Namespaces   map[string]ObjectMetadata `json:"namespaces,omitempty"`
+	Nodes        map[string]ObjectMetadata `json:"nodes,omitempty"`
+	Deployments  map[string]ObjectMetadata `json:"deployments,omitempty"`
+	StatefulSets map[string]ObjectMetadata `json:"statefulSets,omitempty"`
+	DaemonSets   map[string]ObjectMetadata `json:"daemonSets,omitempty"`
+	Jobs         map[string]ObjectMetadata `json:"jobs,omitempty"`
+	// Custom objetos de recursos customizados, por collector; expostos em
+	// /metrics/custom.
+	Custom map[string][]CustomObject `json:"-"`
+	// Certificates certificados TLS coletados; expostos em /metrics/certificates.
+	Certificates []Certificate `json:"-"`
+	// Compliance violações das regras de conformidade; expostas em /compliance.
+	Compliance []ComplianceFinding `json:"-"`
+
+	filter  *k8s.NamespaceFilter
+	allowed func(string) bool
+	// listed resultado do filtro para cada namespace listado.
+	listed map[string]bool
+	// services services coletados ("namespace/nome"); nil se ServiceCollector
+	// não foi aplicado.
+	services map[string]bool
+}
+
+// PodClasses pods não concluídos por classe de QoS e por PriorityClass.
+type PodClasses struct {
+	QOSClasses      map[string]int `json:"qosClasses"`
+	PriorityClasses map[string]int `json:"priorityClasses"`
+}
+
+func (c PodClasses) add(qos, priority string, n int) PodClasses {
+	if c.QOSClasses == nil {
+		c.QOSClasses, c.PriorityClasses = map[string]int{}, map[string]int{}
+	}
+	c.QOSClasses[qos] += n
+	c.PriorityClasses[priority] += n
+	return c
+}
+
+// ObjectMetadata labels e annotations expostos de um objeto.
+type ObjectMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// NewSnapshot cria Snapshot vazio. allowed indica os namespaces visíveis na
+// resposta JSON; nil permite todos.
+func NewSnapshot(filter *k8s.NamespaceFilter, allowed func(string) bool) *Snapshot {
+	if allowed == nil {
+		allowed = func(string) bool { return true }
+	}
+	return &Snapshot{PodPhases: map[string]int{}, filter: filter, allowed: allowed, listed: map[string]bool{}}
+}
+
+// Select aplica o filtro ao namespace listado e guarda o resultado para Selected.
+func (s *Snapshot) Select(ns string, nsLabels map[string]string) bool {
+	s.listed[ns] = s.filter.Match(ns, nsLabels)
+	return s.listed[ns]
+}
+
+// Selected indica se o namespace é coletado. Namespaces não listados
+// (rejeitados pelo label selector no API server, criados após o List ou sem
+// listagem disponível) são avaliados apenas pelo nome; com label selector,
+// não são coletados, pois seus labels são desconhecidos.
+func (s *Snapshot) Selected(ns string) bool {
+	if ok, seen := s.listed[ns]; seen {
+		return ok
+	}
+	return !s.filter.HasLabelSelector() && s.filter.Match(ns, nil)
+}
+
+// Allowed indica se o namespace aparece na resposta JSON.
+func (s *Snapshot) Allowed(ns string) bool { return s.allowed(ns) }
+
+// objectMetadata registra as séries de labels e annotations do objeto e
+// retorna os itens permitidos; ok é falso quando nenhum é exposto.
+func objectMetadata(labels, annotations *InfoVec, obj metav1.ObjectMeta, values ...string) (ObjectMetadata, bool) {
+	meta := ObjectMetadata{
+		Labels:      labels.Set(obj.Labels, values...),
+		Annotations: annotations.Set(obj.Annotations, values...),
+	}
+	return meta, meta.Labels != nil || meta.Annotations != nil
+}
+
+// listRule regra RBAC de listagem dos recursos de um grupo de API.
+func listRule(group string, resources ...string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{APIGroups: []string{group}, Resources: resources, Verbs: []string{VerbList}}
+}
@@ -0,0 +1,241 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+
+	"k8s-metrics-api/internal/config"
+	"k8s-metrics-api/internal/k8s"
+)
+
+type stubCollector struct{ name string }
+
+func (c stubCollector) Name() string                                   { return c.name }
+func (stubCollector) Rules() []rbacv1.PolicyRule                       { return nil }
+func (stubCollector) Describe() []string                               { return nil }
+func (stubCollector) Collect(context.Context, *Source) (Result, error) { return nil, nil }
+
+func names(cs []Collector) []string {
+	out := make([]string, 0, len(cs))
+	for _, c := range cs {
+		out = append(out, c.Name())
+	}
+	return out
+}
+
+func TestRegistrySelect(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     []string
+		disabled    []string
+		expected    []string
+		expectError string
+	}{
+		{name: "should select all by default", expected: []string{"namespaces", "nodes", "pods", "deployments", "services", "ingresses", "tlssecrets"}},
+		{name: "should keep registry order", enabled: []string{"services", "namespaces"}, expected: []string{"namespaces", "services"}},
+		{name: "should drop disabled", disabled: []string{"pods", "nodes"}, expected: []string{"namespaces", "deployments", "services", "ingresses", "tlssecrets"}},
+		{name: "should reject unknown names", disabled: []string{"statefulsets"}, expectError: "statefulsets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			r := DefaultRegistry()
+
+			// Act
+			cs, err := r.Select(tt.enabled, tt.disabled)
+
+			// Assert
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, names(cs))
+		})
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	// Arrange
+	r := DefaultRegistry()
+
+	// Act
+	r.Register(stubCollector{name: "statefulsets"})
+	r.Register(stubCollector{name: "pods"})
+
+	// Assert
+	assert.Equal(t, []string{"namespaces", "nodes", "pods", "deployments", "services", "ingresses", "tlssecrets", "statefulsets"}, r.Names())
+	cs, err := r.Select([]string{"pods"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, stubCollector{name: "pods"}, cs[0])
+}
+
+// builtinCollectors collectors do DefaultRegistry e os opcionais.
+func builtinCollectors() []Collector {
+	return append(DefaultRegistry().collectors, CertManagerCollector{}, StatefulSetCollector{}, DaemonSetCollector{})
+}
+
+func TestDefaultCollectorsRBAC(t *testing.T) {
+	// Arrange: o template do chart sem as linhas com diretivas Helm.
+	b, err := os.ReadFile("../../charts/templates/clusterrole.yaml")
+	require.NoError(t, err)
+	var lines []string
+	for _, l := range strings.Split(string(b), "\n") {
+		if !strings.Contains(l, "{{") {
+			lines = append(lines, l)
+		}
+	}
+	var role rbacv1.ClusterRole
+	require.NoError(t, yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &role))
+	granted := map[string]bool{}
+	for _, rule := range role.Rules {
+		for _, g := range rule.APIGroups {
+			for _, res := range rule.Resources {
+				for _, v := range rule.Verbs {
+					granted[g+"/"+res+"/"+v] = true
+				}
+			}
+		}
+	}
+
+	// Act & Assert: statefulsets e daemonsets também são concedidos, para que
+	// o allowlist de metadados funcione sem rbac.extraRules.
+	for _, c := range append(DefaultRegistry().collectors, StatefulSetCollector{}, DaemonSetCollector{}) {
+		for _, rule := range c.Rules() {
+			for _, g := range rule.APIGroups {
+				for _, res := range rule.Resources {
+					for _, v := range rule.Verbs {
+						assert.True(t, granted[g+"/"+res+"/"+v], "%s: %s/%s %s", c.Name(), g, res, v)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDefaultCollectorsDescribeKnownFamilies(t *testing.T) {
+	// Arrange
+	known := map[string]bool{
+		"k8s_nodes_total": true, "k8s_pods_total": true, "k8s_deployments_total": true,
+		"k8s_services_total": true, "k8s_namespaces_total": true, "k8s_ingresses_total": true,
+		"k8s_namespace_labels": true, "k8s_namespace_annotations": true,
+		"k8s_node_labels": true, "k8s_node_annotations": true,
+		"k8s_deployment_labels": true, "k8s_deployment_annotations": true,
+		"k8s_statefulset_labels": true, "k8s_statefulset_annotations": true,
+		"k8s_daemonset_labels": true, "k8s_daemonset_annotations": true,
+		"k8s_job_labels": true, "k8s_job_annotations": true,
+		"k8s_tls_secret_expiry_timestamp_seconds": true, "k8s_certmanager_certificate_expiry_timestamp_seconds": true,
+	}
+	for f := range families {
+		known[f] = true
+	}
+
+	// Act & Assert
+	seen := map[string]string{}
+	for _, c := range builtinCollectors() {
+		for _, f := range c.Describe() {
+			assert.True(t, known[f], "%s: %s", c.Name(), f)
+			assert.NotContains(t, seen, f, "%s descrita por %s e %s", f, seen[f], c.Name())
+			seen[f] = c.Name()
+		}
+	}
+	assert.Len(t, seen, len(known))
+}
+
+func TestSnapshotSelectedWithLabelSelector(t *testing.T) {
+	tests := []struct {
+		name           string
+		filter         config.NamespaceFilterConfig
+		namespacesFail bool
+		expected       map[string]float64
+	}{
+		{
+			name:     "should exclude namespaces rejected by negated selector",
+			filter:   config.NamespaceFilterConfig{LabelSelector: "env!=prod"},
+			expected: map[string]float64{"dev": 1, "ci": 1},
+		},
+		{
+			name:     "should exclude namespaces rejected by absence selector",
+			filter:   config.NamespaceFilterConfig{LabelSelector: "!ci"},
+			expected: map[string]float64{"dev": 1, "prod": 1},
+		},
+		{
+			name:           "should select nothing when namespaces fail with selector",
+			filter:         config.NamespaceFilterConfig{LabelSelector: "env!=prod"},
+			namespacesFail: true,
+			expected:       map[string]float64{},
+		},
+		{
+			name:           "should match by name when namespaces fail without selector",
+			filter:         config.NamespaceFilterConfig{Exclude: []string{"ci"}},
+			namespacesFail: true,
+			expected:       map[string]float64{"dev": 1, "prod": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			namespace := func(name string, nsLabels map[string]string) *corev1.Namespace {
+				return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nsLabels}}
+			}
+			pod := func(ns string) *corev1.Pod {
+				return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "app"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+			}
+			clientset := fake.NewSimpleClientset(
+				namespace("prod", map[string]string{"env": "prod"}),
+				namespace("dev", map[string]string{"env": "dev"}),
+				namespace("ci", map[string]string{"ci": "true"}),
+				pod("prod"), pod("dev"), pod("ci"),
+			)
+			if tt.namespacesFail {
+				clientset.PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("namespaces is forbidden")
+				})
+			}
+			filter, err := k8s.NewNamespaceFilter(tt.filter)
+			require.NoError(t, err)
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+			src := &Source{Client: &k8s.Client{Clientset: clientset}, Filter: filter, Metrics: m.Collector, Log: logger}
+			snap := NewSnapshot(filter, nil)
+
+			// Act
+			for _, c := range []Collector{NamespaceCollector{}, PodCollector{}} {
+				res, err := c.Collect(context.Background(), src)
+				if err != nil {
+					continue
+				}
+				res.Apply(snap, m)
+			}
+
+			// Assert
+			got := map[string]float64{}
+			for _, ns := range []string{"prod", "dev", "ci"} {
+				if v := testutil.ToFloat64(m.PodStatus.WithLabelValues(ns, "Running")); v > 0 {
+					got[ns] = v
+				}
+			}
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, len(tt.expected), snap.PodCount)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package metrics
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NamespaceCollector conta os namespaces e aplica o filtro de namespaces.
+type NamespaceCollector struct{}
+
+func (NamespaceCollector) Name() string { return "namespaces" }
+
+func (NamespaceCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{listRule("", "namespaces")}
+}
+
+func (NamespaceCollector) Describe() []string {
+	return []string{"k8s_namespaces_total", "k8s_namespace_labels", "k8s_namespace_annotations"}
+}
+
+// Collect lê do cache do API server (resourceVersion=0): lista pequena, em
+// que um pequeno atraso é aceitável.
+func (c NamespaceCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	opts := src.Filter.NamespaceListOptions()
+	opts.ResourceVersion = "0"
+	items, err := List[corev1.Namespace](ctx, src, c.Name(), ClusterScope, opts, func(ctx context.Context, _ string, o metav1.ListOptions) (runtime.Object, error) {
+		return src.Client.Clientset.CoreV1().Namespaces().List(ctx, o)
+	})
+	return namespaceResult(items), err
+}
+
+type namespaceResult []corev1.Namespace
+
+func (r namespaceResult) Apply(s *Snapshot, m *PrometheusMetrics) {
+	count := 0
+	meta := map[string]ObjectMetadata{}
+	m.NamespaceLabels.Reset()
+	m.NamespaceAnnotations.Reset()
+	for _, ns := range r {
+		if !s.Select(ns.Name, ns.Labels) {
+			continue
+		}
+		count++
+		md, ok := objectMetadata(m.NamespaceLabels, m.NamespaceAnnotations, ns.ObjectMeta, ns.Name)
+		if s.Allowed(ns.Name) {
+			s.NamespaceCount++
+			if ok {
+				meta[ns.Name] = md
+			}
+		}
+	}
+	m.NamespaceCount.Set(float64(count))
+	s.Namespaces = meta
+}
+
+// NodeCollector estado e capacidade dos nós.
+type NodeCollector struct{}
+
+func (NodeCollector) Name() string { return "nodes" }
+
+func (NodeCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{listRule("", "nodes")}
+}
+
+func (NodeCollector) Describe() []string {
+	return []string{
+		"k8s_nodes_total", "k8s_node_status_ready", "k8s_node_cpu_allocatable_cores",
+		"k8s_node_memory_allocatable_bytes", "k8s_node_labels", "k8s_node_annotations",
+	}
+}
+
+// Collect lê do cache do API server, como NamespaceCollector.
+func (c NodeCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	items, err := List[corev1.Node](ctx, src, c.Name(), ClusterScope, metav1.ListOptions{ResourceVersion: "0"}, func(ctx context.Context, _ string, o metav1.ListOptions) (runtime.Object, error) {
+		return src.Client.Clientset.CoreV1().Nodes().List(ctx, o)
+	})
+	return nodeResult(items), err
+}
+
+type nodeResult []corev1.Node
+
+func (r nodeResult) Apply(s *Snapshot, m *PrometheusMetrics) {
+	m.NodeCount.Set(float64(len(r)))
+	m.NodeReady.Reset()
+	m.CPUAllocatable.Reset()
+	m.MemoryAllocatable.Reset()
+	m.NodeLabels.Reset()
+	m.NodeAnnotations.Reset()
+	meta := map[string]ObjectMetadata{}
+	for _, n := range r {
+		if md, ok := objectMetadata(m.NodeLabels, m.NodeAnnotations, n.ObjectMeta, n.Name); ok {
+			meta[n.Name] = md
+		}
+		ready := false
+		for _, cond := range n.Status.Conditions {
+			if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+				ready = true
+				break
+			}
+		}
+		m.NodeReady.WithLabelValues(n.Name).Add(boolToFloat(ready))
+		m.CPUAllocatable.WithLabelValues(n.Name).Add(float64(n.Status.Allocatable.Cpu().MilliValue()) / 1000)
+		m.MemoryAllocatable.WithLabelValues(n.Name).Add(float64(n.Status.Allocatable.Memory().Value()))
+	}
+	s.NodeCount = len(r)
+	s.Nodes = meta
+}
+
+// DeploymentCollector réplicas desejadas e disponíveis dos deployments e a
+// regra de conformidade single_replica_without_pdb, pelos PodDisruptionBudgets.
+type DeploymentCollector struct{}
+
+func (DeploymentCollector) Name() string { return "deployments" }
+
+func (DeploymentCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		listRule("apps", "deployments"),
+		listRule("policy", "poddisruptionbudgets"),
+	}
+}
+
+func (DeploymentCollector) Describe() []string {
+	return []string{
+		"k8s_deployments_total", "k8s_deployment_replicas_desired", "k8s_deployment_replicas_available",
+		"k8s_deployment_labels", "k8s_deployment_annotations", "k8s_deployment_compliance_violations",
+	}
+}
+
+// Collect lista os PodDisruptionBudgets apenas com a regra habilitada. Sem
+// eles, a regra deixa de ser avaliada.
+func (c DeploymentCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	cs := src.Client.Clientset
+	scopes, opts := src.Filter.Scopes(), src.Filter.ListOptions()
+	var pdbs []policyv1.PodDisruptionBudget
+	checkPDB := src.Compliance.Enabled(RuleSingleReplicaWithoutPDB)
+	if checkPDB {
+		var err error
+		pdbs, err = List[policyv1.PodDisruptionBudget](ctx, src, "poddisruptionbudgets", scopes, opts, func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+			return cs.PolicyV1().PodDisruptionBudgets(ns).List(ctx, o)
+		})
+		if err != nil {
+			src.Log.WarnContext(ctx, "Erro ao listar PodDisruptionBudgets, regra single_replica_without_pdb não avaliada", "error", err)
+			checkPDB = false
+		}
+	}
+	items, err := List[appsv1.Deployment](ctx, src, c.Name(), scopes, opts, func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return cs.AppsV1().Deployments(ns).List(ctx, o)
+	})
+	res := deploymentResult{items: items}
+	if checkPDB {
+		res.withoutPDB = withoutPDB(items, pdbs)
+	}
+	return res, err
+}
+
+type deploymentResult struct {
+	items []appsv1.Deployment
+	// withoutPDB deployments com uma réplica sem PodDisruptionBudget.
+	withoutPDB []appsv1.Deployment
+}
+
+func (r deploymentResult) Apply(s *Snapshot, m *PrometheusMetrics) {
+	m.DeploymentDesired.Reset()
+	m.DeploymentAvailable.Reset()
+	m.DeploymentLabels.Reset()
+	m.DeploymentAnnotations.Reset()
+	m.DeploymentComplianceViolations.Reset()
+	count := 0
+	meta := map[string]ObjectMetadata{}
+	for _, d := range r.items {
+		if !s.Selected(d.Namespace) {
+			continue
+		}
+		count++
+		md, ok := objectMetadata(m.DeploymentLabels, m.DeploymentAnnotations, d.ObjectMeta, d.Namespace, d.Name)
+		if s.Allowed(d.Namespace) {
+			s.DeploymentCount++
+			if ok {
+				meta[d.Namespace+"/"+d.Name] = md
+			}
+		}
+		m.DeploymentDesired.WithLabelValues(d.Namespace, d.Name).Add(float64(*d.Spec.Replicas))
+		m.DeploymentAvailable.WithLabelValues(d.Namespace, d.Name).Add(float64(d.Status.AvailableReplicas))
+	}
+	m.DeploymentCount.Set(float64(count))
+	s.Deployments = meta
+	for _, d := range r.withoutPDB {
+		if !s.Selected(d.Namespace) {
+			continue
+		}
+		m.DeploymentComplianceViolations.WithLabelValues(d.Namespace, RuleSingleReplicaWithoutPDB).Add(1)
+		if s.Allowed(d.Namespace) {
+			s.Compliance = append(s.Compliance, ComplianceFinding{
+				Namespace: d.Namespace, Kind: "Deployment", Name: d.Name, Rule: RuleSingleReplicaWithoutPDB,
+			})
+		}
+	}
+	sortFindings(s.Compliance)
+}
+
+// StatefulSetCollector labels e annotations dos StatefulSets. Não faz parte
+// do DefaultRegistry: é registrado quando o allowlist de metadados inclui
+// statefulsets.
+type StatefulSetCollector struct{}
+
+func (StatefulSetCollector) Name() string { return "statefulsets" }
+
+func (StatefulSetCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{listRule("apps", "statefulsets")}
+}
+
+func (StatefulSetCollector) Describe() []string {
+	return []string{"k8s_statefulset_labels", "k8s_statefulset_annotations"}
+}
+
+func (c StatefulSetCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	cs := src.Client.Clientset
+	items, err := List[appsv1.StatefulSet](ctx, src, c.Name(), src.Filter.Scopes(), src.Filter.ListOptions(), func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return cs.AppsV1().StatefulSets(ns).List(ctx, o)
+	})
+	res := workloadMetadata{kind: "StatefulSet"}
+	for _, item := range items {
+		res.items = append(res.items, item.ObjectMeta)
+	}
+	return res, err
+}
+
+// DaemonSetCollector labels e annotations dos DaemonSets. Não faz parte do
+// DefaultRegistry: é registrado quando o allowlist de metadados inclui
+// daemonsets.
+type DaemonSetCollector struct{}
+
+func (DaemonSetCollector) Name() string { return "daemonsets" }
+
+func (DaemonSetCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{listRule("apps", "daemonsets")}
+}
+
+func (DaemonSetCollector) Describe() []string {
+	return []string{"k8s_daemonset_labels", "k8s_daemonset_annotations"}
+}
+
+func (c DaemonSetCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	cs := src.Client.Clientset
+	items, err := List[appsv1.DaemonSet](ctx, src, c.Name(), src.Filter.Scopes(), src.Filter.ListOptions(), func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return cs.AppsV1().DaemonSets(ns).List(ctx, o)
+	})
+	res := workloadMetadata{kind: "DaemonSet"}
+	for _, item := range items {
+		res.items = append(res.items, item.ObjectMeta)
+	}
+	return res, err
+}
+
+// workloadMetadata objetos de um tipo de workload listado apenas para o
+// allowlist de metadados.
+type workloadMetadata struct {
+	kind  string
+	items []metav1.ObjectMeta
+}
+
+func (r workloadMetadata) Apply(s *Snapshot, m *PrometheusMetrics) {
+	if r.kind == "DaemonSet" {
+		s.DaemonSets = applyMetadata(s, m.DaemonSetLabels, m.DaemonSetAnnotations, r.items)
+		return
+	}
+	s.StatefulSets = applyMetadata(s, m.StatefulSetLabels, m.StatefulSetAnnotations, r.items)
+}
+
+// applyMetadata substitui as séries de labels e annotations pelas dos objetos
+// dos namespaces selecionados e retorna os itens visíveis na resposta JSON,
+// por "namespace/nome".
+func applyMetadata(s *Snapshot, labels, annotations *InfoVec, items []metav1.ObjectMeta) map[string]ObjectMetadata {
+	labels.Reset()
+	annotations.Reset()
+	meta := map[string]ObjectMetadata{}
+	for _, obj := range items {
+		if !s.Selected(obj.Namespace) {
+			continue
+		}
+		md, ok := objectMetadata(labels, annotations, obj, obj.Namespace, obj.Name)
+		if ok && s.Allowed(obj.Namespace) {
+			meta[obj.Namespace+"/"+obj.Name] = md
+		}
+	}
+	return meta
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s-metrics-api/internal/config"
+	"k8s-metrics-api/internal/k8s"
+)
+
+func TestWorkloadMetadata(t *testing.T) {
+	meta := func(ns, name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: ns, Name: name, Labels: map[string]string{"team": "data", "app": name}}
+	}
+	tests := []struct {
+		name      string
+		collector Collector
+		resource  string
+		family    string
+		snapshot  func(*Snapshot) map[string]ObjectMetadata
+		expected  string
+	}{
+		{
+			name:      "should expose statefulset labels",
+			collector: StatefulSetCollector{},
+			resource:  config.ResourceStatefulSets,
+			family:    "k8s_statefulset_labels",
+			snapshot:  func(s *Snapshot) map[string]ObjectMetadata { return s.StatefulSets },
+			expected: `
+# HELP k8s_statefulset_labels Labels Kubernetes do statefulset
+# TYPE k8s_statefulset_labels gauge
+k8s_statefulset_labels{cluster="default",label_team="data",namespace="shop",statefulset="db"} 1
+k8s_statefulset_labels{cluster="default",label_team="data",namespace="ci",statefulset="db"} 1
+`,
+		},
+		{
+			name:      "should expose daemonset labels",
+			collector: DaemonSetCollector{},
+			resource:  config.ResourceDaemonSets,
+			family:    "k8s_daemonset_labels",
+			snapshot:  func(s *Snapshot) map[string]ObjectMetadata { return s.DaemonSets },
+			expected: `
+# HELP k8s_daemonset_labels Labels Kubernetes do daemonset
+# TYPE k8s_daemonset_labels gauge
+k8s_daemonset_labels{cluster="default",daemonset="db",label_team="data",namespace="shop"} 1
+k8s_daemonset_labels{cluster="default",daemonset="db",label_team="data",namespace="ci"} 1
+`,
+		},
+		{
+			name:      "should expose job labels from the pods collector",
+			collector: PodCollector{},
+			resource:  config.ResourceJobs,
+			family:    "k8s_job_labels",
+			snapshot:  func(s *Snapshot) map[string]ObjectMetadata { return s.Jobs },
+			expected: `
+# HELP k8s_job_labels Labels Kubernetes do job
+# TYPE k8s_job_labels gauge
+k8s_job_labels{cluster="default",job="db",label_team="data",namespace="shop"} 1
+k8s_job_labels{cluster="default",job="db",label_team="data",namespace="ci"} 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			clientset := fake.NewSimpleClientset(
+				&appsv1.StatefulSet{ObjectMeta: meta("shop", "db")},
+				&appsv1.StatefulSet{ObjectMeta: meta("ci", "db")},
+				&appsv1.DaemonSet{ObjectMeta: meta("shop", "db")},
+				&appsv1.DaemonSet{ObjectMeta: meta("ci", "db")},
+				&batchv1.Job{ObjectMeta: meta("shop", "db")},
+				&batchv1.Job{ObjectMeta: meta("ci", "db")},
+			)
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			reg := prometheus.NewRegistry()
+			m := NewClusterMetrics(reg, "default", logger, WithMetadataAllowlist(config.MetadataAllowlistConfig{
+				Labels: map[string][]string{tt.resource: {"team"}},
+			}))
+			src := &Source{Client: &k8s.Client{Clientset: clientset}, Metrics: m.Collector, Log: logger}
+			snap := NewSnapshot(nil, func(ns string) bool { return ns == "shop" })
+
+			// Act
+			res, err := tt.collector.Collect(context.Background(), src)
+			require.NoError(t, err)
+			res.Apply(snap, m)
+
+			// Assert
+			assert.Equal(t, map[string]ObjectMetadata{"shop/db": {Labels: map[string]string{"team": "data"}}}, tt.snapshot(snap))
+			assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(tt.expected), tt.family))
+		})
+	}
+}
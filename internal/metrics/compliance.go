@@ -0,0 +1,235 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"k8s-metrics-api/internal/k8s"
+)
+
+// Regras de conformidade. As de container são avaliadas em cada container
+// (exceto init containers), as de pod em cada pod e a de deployment em cada
+// Deployment.
+const (
+	RuleMissingRequests         = "missing_requests"
+	RuleMissingLimits           = "missing_limits"
+	RuleMissingLivenessProbe    = "missing_liveness_probe"
+	RuleMissingReadinessProbe   = "missing_readiness_probe"
+	RuleRunAsRoot               = "run_as_root"
+	RulePrivileged              = "privileged"
+	RuleHostNetwork             = "host_network"
+	RuleSingleReplicaWithoutPDB = "single_replica_without_pdb"
+)
+
+// complianceRules todas as regras, na ordem do relatório.
+var complianceRules = []string{
+	RuleMissingRequests, RuleMissingLimits, RuleMissingLivenessProbe, RuleMissingReadinessProbe,
+	RuleRunAsRoot, RulePrivileged, RuleHostNetwork, RuleSingleReplicaWithoutPDB,
+}
+
+// ComplianceRules regras de conformidade habilitadas. Um ComplianceRules nil
+// não avalia nenhuma regra.
+type ComplianceRules struct {
+	enabled map[string]bool
+}
+
+// NewComplianceRules habilita as regras em enabled (vazio: todas) que não
+// estão em disabled. Nomes desconhecidos são rejeitados.
+func NewComplianceRules(enabled, disabled []string) (*ComplianceRules, error) {
+	for _, name := range append(append([]string{}, enabled...), disabled...) {
+		if !contains(complianceRules, name) {
+			return nil, fmt.Errorf("regra de conformidade desconhecida: %s (disponíveis: %v)", name, complianceRules)
+		}
+	}
+	r := &ComplianceRules{enabled: map[string]bool{}}
+	for _, name := range complianceRules {
+		if (len(enabled) == 0 || contains(enabled, name)) && !contains(disabled, name) {
+			r.enabled[name] = true
+		}
+	}
+	return r, nil
+}
+
+// DefaultComplianceRules todas as regras habilitadas.
+func DefaultComplianceRules() *ComplianceRules {
+	r, _ := NewComplianceRules(nil, nil)
+	return r
+}
+
+// Enabled indica se a regra está habilitada.
+func (r *ComplianceRules) Enabled(rule string) bool { return r != nil && r.enabled[rule] }
+
+// Names regras habilitadas, na ordem do relatório.
+func (r *ComplianceRules) Names() []string {
+	out := []string{}
+	for _, name := range complianceRules {
+		if r.Enabled(name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// ComplianceFinding violação de uma regra por um workload (ou container de
+// um workload) na resposta JSON. Pods conta os pods afetados; zero para a
+// regra de deployment.
+type ComplianceFinding struct {
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
+	Rule      string `json:"rule"`
+	Pods      int    `json:"pods,omitempty"`
+}
+
+type findingKey struct {
+	namespace, kind, name, container, rule string
+}
+
+// podViolations regras violadas pelo pod e por cada um de seus containers.
+func (r *ComplianceRules) podViolations(p *corev1.Pod) (pod []string, containers map[string][]string) {
+	if r.Enabled(RuleHostNetwork) && p.Spec.HostNetwork {
+		pod = append(pod, RuleHostNetwork)
+	}
+	containers = map[string][]string{}
+	for _, c := range p.Spec.Containers {
+		var rules []string
+		add := func(rule string, violated bool) {
+			if violated && r.Enabled(rule) {
+				rules = append(rules, rule)
+			}
+		}
+		req, lim := c.Resources.Requests, c.Resources.Limits
+		add(RuleMissingRequests, req.Cpu().IsZero() || req.Memory().IsZero())
+		add(RuleMissingLimits, lim.Cpu().IsZero() || lim.Memory().IsZero())
+		add(RuleMissingLivenessProbe, c.LivenessProbe == nil)
+		add(RuleMissingReadinessProbe, c.ReadinessProbe == nil)
+		add(RuleRunAsRoot, runsAsRoot(p, c))
+		add(RulePrivileged, c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged)
+		if len(rules) > 0 {
+			containers[c.Name] = rules
+		}
+	}
+	return pod, containers
+}
+
+// runsAsRoot indica se o container pode executar como root: runAsUser 0 ou,
+// sem runAsUser, sem runAsNonRoot. O container sobrepõe o pod.
+func runsAsRoot(p *corev1.Pod, c corev1.Container) bool {
+	var nonRoot *bool
+	var user *int64
+	if sc := p.Spec.SecurityContext; sc != nil {
+		nonRoot, user = sc.RunAsNonRoot, sc.RunAsUser
+	}
+	if sc := c.SecurityContext; sc != nil {
+		if sc.RunAsNonRoot != nil {
+			nonRoot = sc.RunAsNonRoot
+		}
+		if sc.RunAsUser != nil {
+			user = sc.RunAsUser
+		}
+	}
+	if user != nil {
+		return *user == 0
+	}
+	return nonRoot == nil || !*nonRoot
+}
+
+// complianceSummary pods em violação das regras de pod e container, por
+// workload, container e regra.
+type complianceSummary struct {
+	rules *ComplianceRules
+	pods  map[findingKey]int
+}
+
+func newComplianceSummary(rules *ComplianceRules) *complianceSummary {
+	return &complianceSummary{rules: rules, pods: map[findingKey]int{}}
+}
+
+// add avalia pods ainda em execução.
+func (s *complianceSummary) add(p *corev1.Pod, wl k8s.Workload) {
+	if s.rules == nil || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+		return
+	}
+	podRules, containers := s.rules.podViolations(p)
+	for _, rule := range podRules {
+		s.pods[findingKey{p.Namespace, wl.Kind, wl.Name, "", rule}]++
+	}
+	for container, rules := range containers {
+		for _, rule := range rules {
+			s.pods[findingKey{p.Namespace, wl.Kind, wl.Name, container, rule}]++
+		}
+	}
+}
+
+// apply registra as violações por namespace e regra em vec e acrescenta os
+// findings dos namespaces visíveis ao Snapshot.
+func (s *complianceSummary) apply(snap *Snapshot, vec *GaugeVec) {
+	vec.Reset()
+	for key, n := range s.pods {
+		if !snap.Selected(key.namespace) {
+			continue
+		}
+		vec.WithLabelValues(key.namespace, key.rule).Add(float64(n))
+		if snap.Allowed(key.namespace) {
+			snap.Compliance = append(snap.Compliance, ComplianceFinding{
+				Namespace: key.namespace, Kind: key.kind, Name: key.name,
+				Container: key.container, Rule: key.rule, Pods: n,
+			})
+		}
+	}
+	sortFindings(snap.Compliance)
+}
+
+// withoutPDB deployments com uma réplica cujos pods não são selecionados por
+// nenhum PodDisruptionBudget do namespace. Em policy/v1, um selector vazio
+// (não nil) seleciona todos os pods do namespace.
+func withoutPDB(deployments []appsv1.Deployment, pdbs []policyv1.PodDisruptionBudget) []appsv1.Deployment {
+	var out []appsv1.Deployment
+	for _, d := range deployments {
+		if d.Spec.Replicas != nil && *d.Spec.Replicas != 1 {
+			continue
+		}
+		covered := false
+		for _, pdb := range pdbs {
+			if pdb.Namespace != d.Namespace || pdb.Spec.Selector == nil {
+				continue
+			}
+			sel, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err == nil && sel.Matches(labels.Set(d.Spec.Template.Labels)) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func sortFindings(f []ComplianceFinding) {
+	sort.Slice(f, func(i, j int) bool {
+		a, b := f[i], f[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Container != b.Container {
+			return a.Container < b.Container
+		}
+		return a.Rule < b.Rule
+	})
+}
@@ -0,0 +1,240 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s-metrics-api/internal/k8s"
+)
+
+func TestNewComplianceRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabled       []string
+		disabled      []string
+		expected      []string
+		expectedError bool
+	}{
+		{name: "should enable all rules by default", expected: complianceRules},
+		{name: "should keep only enabled rules", enabled: []string{RulePrivileged, RuleHostNetwork}, expected: []string{RulePrivileged, RuleHostNetwork}},
+		{name: "should drop disabled rules", enabled: []string{RulePrivileged, RuleHostNetwork}, disabled: []string{RuleHostNetwork}, expected: []string{RulePrivileged}},
+		{name: "should reject unknown rule", disabled: []string{"no_probes"}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rules, err := NewComplianceRules(tt.enabled, tt.disabled)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rules.Names())
+		})
+	}
+}
+
+func TestPodViolations(t *testing.T) {
+	yes, no := true, false
+	root, user := int64(0), int64(1000)
+	probe := &corev1.Probe{}
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+	}
+	compliant := corev1.Container{
+		Name: "app", Resources: resources, LivenessProbe: probe, ReadinessProbe: probe,
+		SecurityContext: &corev1.SecurityContext{RunAsNonRoot: &yes},
+	}
+	tests := []struct {
+		name               string
+		mutate             func(*corev1.Pod)
+		expectedPod        []string
+		expectedContainers map[string][]string
+	}{
+		{name: "should accept compliant container", mutate: func(*corev1.Pod) {}},
+		{
+			name: "should report missing resources and probes",
+			mutate: func(p *corev1.Pod) {
+				c := &p.Spec.Containers[0]
+				c.Resources, c.LivenessProbe, c.ReadinessProbe = corev1.ResourceRequirements{}, nil, nil
+			},
+			expectedContainers: map[string][]string{"app": {RuleMissingRequests, RuleMissingLimits, RuleMissingLivenessProbe, RuleMissingReadinessProbe}},
+		},
+		{
+			name:               "should report missing memory limit",
+			mutate:             func(p *corev1.Pod) { delete(p.Spec.Containers[0].Resources.Limits, corev1.ResourceMemory) },
+			expectedContainers: map[string][]string{"app": {RuleMissingLimits}},
+		},
+		{
+			name:               "should report root without runAsNonRoot",
+			mutate:             func(p *corev1.Pod) { p.Spec.Containers[0].SecurityContext = nil },
+			expectedContainers: map[string][]string{"app": {RuleRunAsRoot}},
+		},
+		{
+			name:               "should report explicit root user",
+			mutate:             func(p *corev1.Pod) { p.Spec.Containers[0].SecurityContext.RunAsUser = &root },
+			expectedContainers: map[string][]string{"app": {RuleRunAsRoot}},
+		},
+		{
+			name: "should inherit pod security context",
+			mutate: func(p *corev1.Pod) {
+				p.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsUser: &user, RunAsNonRoot: &no}
+				p.Spec.Containers[0].SecurityContext = nil
+			},
+		},
+		{
+			name:               "should report privileged container and host network",
+			mutate:             func(p *corev1.Pod) { p.Spec.HostNetwork = true; p.Spec.Containers[0].SecurityContext.Privileged = &yes },
+			expectedPod:        []string{RuleHostNetwork},
+			expectedContainers: map[string][]string{"app": {RulePrivileged}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c := compliant
+			c.Resources = *compliant.Resources.DeepCopy()
+			c.SecurityContext = compliant.SecurityContext.DeepCopy()
+			p := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{c}}}
+			tt.mutate(p)
+
+			// Act
+			pod, containers := DefaultComplianceRules().podViolations(p)
+
+			// Assert
+			assert.Equal(t, tt.expectedPod, pod)
+			if tt.expectedContainers == nil {
+				tt.expectedContainers = map[string][]string{}
+			}
+			assert.Equal(t, tt.expectedContainers, containers)
+		})
+	}
+}
+
+func TestPodCollectorCompliance(t *testing.T) {
+	// Arrange
+	privileged := true
+	pod := func(ns, name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent", Controller: &privileged}}},
+			Spec: corev1.PodSpec{HostNetwork: true, Containers: []corev1.Container{{
+				Name: "agent", SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+			}}},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		pod("ops", "agent-1", corev1.PodRunning),
+		pod("ops", "agent-2", corev1.PodRunning),
+		pod("ops", "agent-0", corev1.PodFailed),
+		pod("kube-system", "agent-3", corev1.PodRunning),
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	rules, err := NewComplianceRules([]string{RulePrivileged, RuleHostNetwork}, nil)
+	require.NoError(t, err)
+	src := &Source{Client: &k8s.Client{Clientset: clientset}, Compliance: rules, Metrics: m.Collector, Log: logger}
+	snap := NewSnapshot(nil, func(ns string) bool { return ns == "ops" })
+
+	// Act
+	res, err := PodCollector{}.Collect(context.Background(), src)
+	require.NoError(t, err)
+	res.Apply(snap, m)
+
+	// Assert
+	assert.Equal(t, []ComplianceFinding{
+		{Namespace: "ops", Kind: "DaemonSet", Name: "agent", Rule: RuleHostNetwork, Pods: 2},
+		{Namespace: "ops", Kind: "DaemonSet", Name: "agent", Container: "agent", Rule: RulePrivileged, Pods: 2},
+	}, snap.Compliance)
+	// As métricas consideram todos os namespaces; pods concluídos não contam.
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.PodComplianceViolations.WithLabelValues("ops", RulePrivileged)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.PodComplianceViolations.WithLabelValues("kube-system", RuleHostNetwork)))
+	assert.Equal(t, 4, testutil.CollectAndCount(m.PodComplianceViolations))
+}
+
+func TestDeploymentCollectorSingleReplicaWithoutPDB(t *testing.T) {
+	deployment := func(name string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}}},
+			},
+		}
+	}
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	}
+	tests := []struct {
+		name     string
+		rules    *ComplianceRules
+		pdbError bool
+		// selectAll acrescenta um PDB com selector vazio, que seleciona todo
+		// o namespace.
+		selectAll bool
+		expected  []ComplianceFinding
+	}{
+		{
+			name:     "should report single replica without pdb",
+			rules:    DefaultComplianceRules(),
+			expected: []ComplianceFinding{{Namespace: "shop", Kind: "Deployment", Name: "api", Rule: RuleSingleReplicaWithoutPDB}},
+		},
+		{name: "should accept empty selector as covering the namespace", rules: DefaultComplianceRules(), selectAll: true},
+		{name: "should skip rule when pdbs are forbidden", rules: DefaultComplianceRules(), pdbError: true},
+		{name: "should skip disabled rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			clientset := fake.NewSimpleClientset(deployment("api", 1), deployment("web", 1), deployment("worker", 3), pdb)
+			if tt.selectAll {
+				require.NoError(t, clientset.Tracker().Add(&policyv1.PodDisruptionBudget{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "all"},
+					Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{}},
+				}))
+			}
+			if tt.pdbError {
+				clientset.PrependReactor("list", "poddisruptionbudgets", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("poddisruptionbudgets is forbidden")
+				})
+			}
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+			src := &Source{Client: &k8s.Client{Clientset: clientset}, Compliance: tt.rules, Metrics: m.Collector, Log: logger}
+			snap := NewSnapshot(nil, nil)
+
+			// Act
+			res, err := DeploymentCollector{}.Collect(context.Background(), src)
+			require.NoError(t, err)
+			res.Apply(snap, m)
+
+			// Assert
+			assert.Equal(t, 3, snap.DeploymentCount)
+			assert.Equal(t, tt.expected, snap.Compliance)
+			assert.Equal(t, len(tt.expected), testutil.CollectAndCount(m.DeploymentComplianceViolations))
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"k8s-metrics-api/internal/config"
+)
+
+// CustomObject métricas de um objeto de recurso customizado na resposta JSON.
+type CustomObject struct {
+	Cluster   string             `json:"cluster,omitempty"`
+	Namespace string             `json:"namespace,omitempty"`
+	Name      string             `json:"name"`
+	Labels    map[string]string  `json:"labels,omitempty"`
+	Values    map[string]float64 `json:"values"`
+}
+
+// CustomResourceMetrics famílias de um recurso customizado, uma por métrica
+// configurada (k8s_custom_<name>_<metric>).
+type CustomResourceMetrics struct {
+	vecs map[string]*prometheus.GaugeVec
+}
+
+func customFamily(cr config.CustomResourceConfig, metric string) string {
+	return "k8s_custom_" + cr.Name + "_" + metric
+}
+
+// customLabels labels das séries: namespace (se namespaced), name e os
+// configurados, em ordem alfabética.
+func customLabels(cr config.CustomResourceConfig) []string {
+	var labels []string
+	if !cr.ClusterScoped {
+		labels = append(labels, "namespace")
+	}
+	return append(labels, append([]string{"name"}, sortedKeys(cr.Labels)...)...)
+}
+
+func newCustomResourceMetrics(cr config.CustomResourceConfig) *CustomResourceMetrics {
+	m := &CustomResourceMetrics{vecs: map[string]*prometheus.GaugeVec{}}
+	for _, metric := range cr.Metrics {
+		help := metric.Help
+		if help == "" {
+			help = fmt.Sprintf("Campo %s de %s", metric.Path, cr.Resource)
+		}
+		m.vecs[metric.Name] = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: customFamily(cr, metric.Name), Help: help}, customLabels(cr))
+	}
+	return m
+}
+
+func (m *CustomResourceMetrics) collectors() []prometheus.Collector {
+	out := make([]prometheus.Collector, 0, len(m.vecs))
+	for _, name := range sortedKeys(m.vecs) {
+		out = append(out, m.vecs[name])
+	}
+	return out
+}
+
+// CustomResourceCollector lista um recurso customizado pelo dynamic client e
+// extrai os campos configurados.
+type CustomResourceCollector struct {
+	cfg     config.CustomResourceConfig
+	gvr     schema.GroupVersionResource
+	labels  []string
+	paths   map[string]fieldPath
+	metrics []config.CustomMetricConfig
+}
+
+// NewCustomResourceCollector cria o collector de cr, validando os caminhos de campo.
+func NewCustomResourceCollector(cr config.CustomResourceConfig) (*CustomResourceCollector, error) {
+	c := &CustomResourceCollector{
+		cfg:     cr,
+		gvr:     schema.GroupVersionResource{Group: cr.Group, Version: cr.Version, Resource: cr.Resource},
+		labels:  sortedKeys(cr.Labels),
+		paths:   map[string]fieldPath{},
+		metrics: cr.Metrics,
+	}
+	for label, path := range cr.Labels {
+		p, err := parseFieldPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("recurso %s, label %s: %w", cr.Name, label, err)
+		}
+		c.paths["label:"+label] = p
+	}
+	for _, metric := range cr.Metrics {
+		p, err := parseFieldPath(metric.Path)
+		if err != nil {
+			return nil, fmt.Errorf("recurso %s, métrica %s: %w", cr.Name, metric.Name, err)
+		}
+		c.paths["metric:"+metric.Name] = p
+	}
+	return c, nil
+}
+
+// RegisterCustomResources registra um CustomResourceCollector por item de
+// crs. Nomes já registrados são rejeitados.
+func (r *Registry) RegisterCustomResources(crs []config.CustomResourceConfig) error {
+	for _, cr := range crs {
+		if contains(r.Names(), cr.Name) {
+			return fmt.Errorf("recurso customizado %s: nome já usado por outro collector", cr.Name)
+		}
+		c, err := NewCustomResourceCollector(cr)
+		if err != nil {
+			return err
+		}
+		r.Register(c)
+	}
+	return nil
+}
+
+// Name nome configurado do recurso.
+func (c *CustomResourceCollector) Name() string { return c.cfg.Name }
+
+func (c *CustomResourceCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{listRule(c.gvr.Group, c.gvr.Resource)}
+}
+
+func (c *CustomResourceCollector) Describe() []string {
+	out := make([]string, 0, len(c.metrics))
+	for _, metric := range c.metrics {
+		out = append(out, customFamily(c.cfg, metric.Name))
+	}
+	return out
+}
+
+func (c *CustomResourceCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	scopes, opts := ClusterScope, metav1.ListOptions{}
+	if !c.cfg.ClusterScoped {
+		scopes, opts = src.Filter.Scopes(), src.Filter.ListOptions()
+	}
+	res := &customResult{c: c}
+	err := src.Each(ctx, c.Name(), scopes, opts, func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		if c.cfg.ClusterScoped {
+			return src.Client.Dynamic.Resource(c.gvr).List(ctx, o)
+		}
+		return src.Client.Dynamic.Resource(c.gvr).Namespace(ns).List(ctx, o)
+	}, func(obj runtime.Object) {
+		res.objects = append(res.objects, c.object(obj.(*unstructured.Unstructured)))
+	})
+	return res, err
+}
+
+func (c *CustomResourceCollector) object(u *unstructured.Unstructured) CustomObject {
+	o := CustomObject{Namespace: u.GetNamespace(), Name: u.GetName(), Values: map[string]float64{}}
+	for _, label := range c.labels {
+		v, _ := c.paths["label:"+label].lookup(u.Object)
+		if o.Labels == nil {
+			o.Labels = map[string]string{}
+		}
+		o.Labels[label] = labelValue(v)
+	}
+	for _, metric := range c.metrics {
+		if v, ok := c.paths["metric:"+metric.Name].lookup(u.Object); ok {
+			if f, ok := gaugeValue(v, metric.ValueMap); ok {
+				o.Values[metric.Name] = f
+			}
+		}
+	}
+	return o
+}
+
+type customResult struct {
+	c       *CustomResourceCollector
+	objects []CustomObject
+}
+
+func (r *customResult) Apply(s *Snapshot, m *PrometheusMetrics) {
+	vecs := m.Custom[r.c.Name()]
+	if vecs != nil {
+		for _, v := range vecs.vecs {
+			v.Reset()
+		}
+	}
+	objects := []CustomObject{}
+	for _, o := range r.objects {
+		if !r.c.cfg.ClusterScoped && !s.Selected(o.Namespace) {
+			continue
+		}
+		if vecs != nil {
+			lvs := []string{o.Name}
+			if !r.c.cfg.ClusterScoped {
+				lvs = append([]string{o.Namespace}, lvs...)
+			}
+			for _, label := range r.c.labels {
+				lvs = append(lvs, o.Labels[label])
+			}
+			for name, v := range o.Values {
+				vecs.vecs[name].WithLabelValues(lvs...).Set(v)
+			}
+		}
+		if r.c.cfg.ClusterScoped || s.Allowed(o.Namespace) {
+			objects = append(objects, o)
+		}
+	}
+	if s.Custom == nil {
+		s.Custom = map[string][]CustomObject{}
+	}
+	s.Custom[r.c.Name()] = objects
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"k8s-metrics-api/internal/config"
+	"k8s-metrics-api/internal/k8s"
+)
+
+var rolloutsConfig = config.CustomResourceConfig{
+	Name:     "rollout",
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "rollouts",
+	Labels:   map[string]string{"strategy": "spec.strategy.type"},
+	Metrics: []config.CustomMetricConfig{
+		{Name: "replicas_desired", Path: "spec.replicas"},
+		{Name: "phase", Path: "status.phase", ValueMap: map[string]float64{"Healthy": 1, "Degraded": 0}},
+	},
+}
+
+func rollout(ns, name, strategy string, replicas int64, phase string) *unstructured.Unstructured {
+	obj := map[string]any{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata":   map[string]any{"namespace": ns, "name": name},
+		"spec":       map[string]any{"replicas": replicas, "strategy": map[string]any{"type": strategy}},
+	}
+	if phase != "" {
+		obj["status"] = map[string]any{"phase": phase}
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func TestCustomResourceCollector(t *testing.T) {
+	// Arrange
+	gvr := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "RolloutList"},
+		rollout("shop", "checkout", "canary", 3, "Healthy"),
+		rollout("shop", "cart", "blueGreen", 2, "Paused"),
+		rollout("ci", "runner", "canary", 1, "Degraded"),
+	)
+	c, err := NewCustomResourceCollector(rolloutsConfig)
+	require.NoError(t, err)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger, WithCustomResources([]config.CustomResourceConfig{rolloutsConfig}))
+	src := &Source{Client: &k8s.Client{Dynamic: dyn}, Metrics: m.Collector, Log: logger}
+	snap := NewSnapshot(nil, func(ns string) bool { return ns == "shop" })
+
+	// Act
+	res, err := c.Collect(context.Background(), src)
+	require.NoError(t, err)
+	res.Apply(snap, m)
+
+	// Assert
+	desired := m.Custom["rollout"].vecs["replicas_desired"]
+	phase := m.Custom["rollout"].vecs["phase"]
+	assert.Equal(t, 3, testutil.CollectAndCount(desired, "k8s_custom_rollout_replicas_desired"))
+	assert.Equal(t, 3.0, testutil.ToFloat64(desired.WithLabelValues("shop", "checkout", "canary")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(phase.WithLabelValues("ci", "runner", "canary")))
+	// Valor fora do valueMap não gera série.
+	assert.Equal(t, 2, testutil.CollectAndCount(phase))
+	assert.ElementsMatch(t, []CustomObject{
+		{Namespace: "shop", Name: "checkout", Labels: map[string]string{"strategy": "canary"}, Values: map[string]float64{"replicas_desired": 3, "phase": 1}},
+		{Namespace: "shop", Name: "cart", Labels: map[string]string{"strategy": "blueGreen"}, Values: map[string]float64{"replicas_desired": 2}},
+	}, snap.Custom["rollout"])
+	assert.Equal(t, []string{"k8s_custom_rollout_replicas_desired", "k8s_custom_rollout_phase"}, c.Describe())
+}
+
+func TestRegisterCustomResources(t *testing.T) {
+	tests := []struct {
+		name        string
+		cr          config.CustomResourceConfig
+		expectError string
+	}{
+		{name: "should register collector", cr: rolloutsConfig},
+		{name: "should reject names of other collectors", cr: config.CustomResourceConfig{Name: "pods", Version: "v1", Resource: "pods", Metrics: rolloutsConfig.Metrics}, expectError: "pods"},
+		{name: "should reject invalid paths", cr: config.CustomResourceConfig{Name: "x", Version: "v1", Resource: "xs", Metrics: []config.CustomMetricConfig{{Name: "m", Path: "status."}}}, expectError: "status."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			r := DefaultRegistry()
+
+			// Act
+			err := r.RegisterCustomResources([]config.CustomResourceConfig{tt.cr})
+
+			// Assert
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, r.Names(), tt.cr.Name)
+		})
+	}
+}
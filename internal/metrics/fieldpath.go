@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// fieldPath caminho de campo de um objeto unstructured, como
+// "status.conditions[type=Ready].status" ou "spec.containers[0].image".
+type fieldPath []pathSegment
+
+type pathSegment struct {
+	field string
+	// index posição na lista; -1 sem índice.
+	index int
+	// key e value seletor [key=value] do primeiro item da lista que casa.
+	key, value string
+}
+
+func parseFieldPath(s string) (fieldPath, error) {
+	var p fieldPath
+	for rest := s; rest != ""; {
+		seg := pathSegment{index: -1}
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		seg.field, rest = rest[:end], rest[end:]
+		if seg.field == "" {
+			return nil, fmt.Errorf("caminho %q inválido: campo vazio", s)
+		}
+		if strings.HasPrefix(rest, "[") {
+			closing := strings.IndexByte(rest, ']')
+			if closing < 0 {
+				return nil, fmt.Errorf("caminho %q inválido: seletor sem ]", s)
+			}
+			sel := rest[1:closing]
+			rest = rest[closing+1:]
+			if k, v, ok := strings.Cut(sel, "="); ok {
+				seg.key, seg.value = k, v
+			} else if i, err := strconv.Atoi(sel); err == nil && i >= 0 {
+				seg.index = i
+			} else {
+				return nil, fmt.Errorf("caminho %q inválido: seletor %q", s, sel)
+			}
+		}
+		switch {
+		case rest == "":
+		case strings.HasPrefix(rest, ".") && len(rest) > 1:
+			rest = rest[1:]
+		default:
+			return nil, fmt.Errorf("caminho %q inválido", s)
+		}
+		p = append(p, seg)
+	}
+	if len(p) == 0 {
+		return nil, fmt.Errorf("caminho vazio")
+	}
+	return p, nil
+}
+
+// lookup retorna o valor do campo em obj; ok é falso se algum trecho não existir.
+func (p fieldPath) lookup(obj map[string]any) (any, bool) {
+	var cur any = obj
+	for _, seg := range p {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[seg.field]; !ok {
+			return nil, false
+		}
+		if seg.index < 0 && seg.key == "" {
+			continue
+		}
+		list, ok := cur.([]any)
+		if !ok {
+			return nil, false
+		}
+		if seg.index >= 0 {
+			if seg.index >= len(list) {
+				return nil, false
+			}
+			cur = list[seg.index]
+			continue
+		}
+		found := false
+		for _, item := range list {
+			if im, ok := item.(map[string]any); ok && fmt.Sprint(im[seg.key]) == seg.value {
+				cur, found = item, true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// gaugeValue converte o valor de um campo em valor de gauge.
+func gaugeValue(v any, valueMap map[string]float64) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		return boolToFloat(v), true
+	case string:
+		if valueMap != nil {
+			f, ok := valueMap[v]
+			return f, ok
+		}
+		switch v {
+		case "True", "true":
+			return 1, true
+		case "False", "false":
+			return 0, true
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return float64(t.Unix()), true
+		}
+		if q, err := resource.ParseQuantity(v); err == nil {
+			return q.AsApproximateFloat64(), true
+		}
+	}
+	return 0, false
+}
+
+// labelValue converte o valor de um campo em valor de label; campos
+// ausentes ou compostos resultam em "".
+func labelValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64, float64, bool:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
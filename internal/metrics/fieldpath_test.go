@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldPathLookup(t *testing.T) {
+	obj := map[string]any{
+		"spec": map[string]any{
+			"replicas":   int64(3),
+			"containers": []any{map[string]any{"image": "nginx:1.27"}},
+		},
+		"status": map[string]any{
+			"conditions": []any{
+				map[string]any{"type": "Progressing", "status": "True"},
+				map[string]any{"type": "cert-manager.io/Ready", "status": "False"},
+			},
+		},
+	}
+	tests := []struct {
+		name        string
+		path        string
+		expected    any
+		expectFound bool
+		expectError bool
+	}{
+		{name: "should read nested field", path: "spec.replicas", expected: int64(3), expectFound: true},
+		{name: "should read list index", path: "spec.containers[0].image", expected: "nginx:1.27", expectFound: true},
+		{name: "should select list item by key", path: "status.conditions[type=cert-manager.io/Ready].status", expected: "False", expectFound: true},
+		{name: "should report missing field", path: "status.phase"},
+		{name: "should report index out of range", path: "spec.containers[1].image"},
+		{name: "should report unmatched selector", path: "status.conditions[type=Ready].status"},
+		{name: "should reject empty segment", path: "spec..replicas", expectError: true},
+		{name: "should reject unclosed selector", path: "status.conditions[type=Ready", expectError: true},
+		{name: "should reject invalid selector", path: "spec.containers[x]", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p, err := parseFieldPath(tt.path)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// Act
+			v, ok := p.lookup(obj)
+
+			// Assert
+			assert.Equal(t, tt.expectFound, ok)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestGaugeValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		valueMap map[string]float64
+		expected float64
+		ok       bool
+	}{
+		{name: "should use integers", value: int64(3), expected: 3, ok: true},
+		{name: "should use floats", value: 0.5, expected: 0.5, ok: true},
+		{name: "should convert booleans", value: true, expected: 1, ok: true},
+		{name: "should convert condition status", value: "False", expected: 0, ok: true},
+		{name: "should convert timestamps", value: "2025-08-20T18:30:00Z", expected: 1755714600, ok: true},
+		{name: "should convert quantities", value: "500m", expected: 0.5, ok: true},
+		{name: "should use value map", value: "Healthy", valueMap: map[string]float64{"Healthy": 1, "Degraded": 0}, expected: 1, ok: true},
+		{name: "should skip values outside value map", value: "Unknown", valueMap: map[string]float64{"Healthy": 1}},
+		{name: "should skip other strings", value: "canary"},
+		{name: "should skip objects", value: map[string]any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			v, ok := gaugeValue(tt.value, tt.valueMap)
+
+			// Assert
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"strings"
+
+	"k8s-metrics-api/internal/config"
+)
+
+// Motivos de violação da política de imagens.
+const (
+	ViolationRegistry = "registry_not_allowed"
+	ViolationTag      = "tag_disallowed"
+)
+
+// defaultRegistry registry de imagens sem registry explícito.
+const defaultRegistry = "docker.io"
+
+// ImageRef referência de imagem decomposta. Imagens sem tag nem digest
+// recebem a tag implícita "latest".
+type ImageRef struct {
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+}
+
+// parseImage decompõe uma referência como "ghcr.io/acme/api:1.2@sha256:...",
+// com as mesmas regras do Docker para o registry implícito.
+func parseImage(image string) ImageRef {
+	var ref ImageRef
+	name := image
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name, ref.Digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		name, ref.Tag = name[:i], name[i+1:]
+	}
+	ref.Registry = defaultRegistry
+	if first, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry, name = first, rest
+	} else if !ok {
+		name = "library/" + name
+	}
+	ref.Repository = name
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref
+}
+
+// Latest indica uso da tag latest, explícita ou implícita.
+func (r ImageRef) Latest() bool { return r.Tag == "latest" }
+
+// ImagePolicy política de ImagePolicyConfig. Um ImagePolicy nil não tem regras.
+type ImagePolicy struct {
+	allowedRegistries []string
+	disallowedTags    []string
+}
+
+// NewImagePolicy cria ImagePolicy; nil se cfg não define regras.
+func NewImagePolicy(cfg config.ImagePolicyConfig) *ImagePolicy {
+	if len(cfg.AllowedRegistries) == 0 && len(cfg.DisallowedTags) == 0 {
+		return nil
+	}
+	return &ImagePolicy{allowedRegistries: cfg.AllowedRegistries, disallowedTags: cfg.DisallowedTags}
+}
+
+// Violations motivos pelos quais ref viola a política. A tag é verificada
+// mesmo quando a imagem também é fixada por digest.
+func (p *ImagePolicy) Violations(ref ImageRef) []string {
+	if p == nil {
+		return nil
+	}
+	var out []string
+	if len(p.allowedRegistries) > 0 && !p.registryAllowed(ref) {
+		out = append(out, ViolationRegistry)
+	}
+	if ref.Tag != "" && contains(p.disallowedTags, ref.Tag) {
+		out = append(out, ViolationTag)
+	}
+	return out
+}
+
+// registryAllowed aceita o registry exato ou um prefixo de repositório
+// terminado em "/".
+func (p *ImagePolicy) registryAllowed(ref ImageRef) bool {
+	full := ref.Registry + "/" + ref.Repository
+	for _, allowed := range p.allowedRegistries {
+		if ref.Registry == allowed || strings.HasPrefix(full, allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Image imagem distinta em execução, na resposta JSON.
+type Image struct {
+	Cluster string `json:"cluster,omitempty"`
+	Image   string `json:"image"`
+	ImageRef
+	Latest bool `json:"latest"`
+	// Pods pods com a imagem, no total e por namespace.
+	Pods       int            `json:"pods"`
+	Namespaces map[string]int `json:"namespaces"`
+	// Violations motivos de violação da política de imagens.
+	Violations []string `json:"violations,omitempty"`
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s-metrics-api/internal/config"
+	"k8s-metrics-api/internal/k8s"
+)
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected ImageRef
+	}{
+		{image: "nginx", expected: ImageRef{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{image: "redis:7", expected: ImageRef{Registry: "docker.io", Repository: "library/redis", Tag: "7"}},
+		{image: "bitnami/redis:7.2", expected: ImageRef{Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2"}},
+		{image: "ghcr.io/acme/api:1.2.3", expected: ImageRef{Registry: "ghcr.io", Repository: "acme/api", Tag: "1.2.3"}},
+		{image: "registry.local:5000/app", expected: ImageRef{Registry: "registry.local:5000", Repository: "app", Tag: "latest"}},
+		{image: "localhost/app:dev", expected: ImageRef{Registry: "localhost", Repository: "app", Tag: "dev"}},
+		{image: "ghcr.io/acme/api@sha256:abc", expected: ImageRef{Registry: "ghcr.io", Repository: "acme/api", Digest: "sha256:abc"}},
+		{image: "ghcr.io/acme/api:1.2@sha256:abc", expected: ImageRef{Registry: "ghcr.io", Repository: "acme/api", Tag: "1.2", Digest: "sha256:abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			// Act
+			ref := parseImage(tt.image)
+
+			// Assert
+			assert.Equal(t, tt.expected, ref)
+		})
+	}
+}
+
+func TestImagePolicyViolations(t *testing.T) {
+	policy := NewImagePolicy(config.ImagePolicyConfig{
+		AllowedRegistries: []string{"registry.local:5000", "ghcr.io/acme"},
+		DisallowedTags:    []string{"latest"},
+	})
+	tests := []struct {
+		name     string
+		policy   *ImagePolicy
+		image    string
+		expected []string
+	}{
+		{name: "should allow registry", policy: policy, image: "registry.local:5000/app:1"},
+		{name: "should allow repository prefix", policy: policy, image: "ghcr.io/acme/api:1"},
+		{name: "should not match partial path", policy: policy, image: "ghcr.io/acme-evil/api:1", expected: []string{ViolationRegistry}},
+		{name: "should report implicit latest", policy: policy, image: "nginx", expected: []string{ViolationRegistry, ViolationTag}},
+		{name: "should accept digest without tag", policy: policy, image: "ghcr.io/acme/api@sha256:abc"},
+		{name: "should ignore nil policy", image: "nginx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := tt.policy.Violations(parseImage(tt.image))
+
+			// Assert
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestPodCollectorImageInventory(t *testing.T) {
+	// Arrange
+	pod := func(ns, name string, phase corev1.PodPhase, images ...string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}, Status: corev1.PodStatus{Phase: phase}}
+		for i, img := range images {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: string(rune('a' + i)), Image: img})
+		}
+		return p
+	}
+	clientset := fake.NewSimpleClientset(
+		pod("shop", "api-1", corev1.PodRunning, "ghcr.io/acme/api:1.2", "nginx"),
+		pod("shop", "api-2", corev1.PodRunning, "ghcr.io/acme/api:1.2", "ghcr.io/acme/api:1.2"),
+		pod("ci", "job-1", corev1.PodPending, "nginx:latest"),
+		pod("ci", "job-0", corev1.PodSucceeded, "docker.io/library/busybox"),
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	policy := NewImagePolicy(config.ImagePolicyConfig{AllowedRegistries: []string{"ghcr.io"}, DisallowedTags: []string{"latest"}})
+	src := &Source{Client: &k8s.Client{Clientset: clientset}, Images: policy, Metrics: m.Collector, Log: logger}
+	snap := NewSnapshot(nil, func(ns string) bool { return ns == "shop" })
+
+	// Act
+	res, err := PodCollector{}.Collect(context.Background(), src)
+	require.NoError(t, err)
+	res.Apply(snap, m)
+
+	// Assert
+	violation := []string{ViolationRegistry, ViolationTag}
+	assert.Equal(t, []Image{
+		{Image: "ghcr.io/acme/api:1.2", ImageRef: ImageRef{Registry: "ghcr.io", Repository: "acme/api", Tag: "1.2"}, Pods: 2, Namespaces: map[string]int{"shop": 2}},
+		{Image: "nginx", ImageRef: ImageRef{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}, Latest: true, Pods: 1, Namespaces: map[string]int{"shop": 1}, Violations: violation},
+	}, snap.Images)
+	assert.Equal(t, map[string]int{ViolationRegistry: 1, ViolationTag: 1}, snap.ImageViolations)
+	// As métricas consideram todos os namespaces; pods concluídos não contam.
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.ImagePolicyViolations.WithLabelValues("ci", ViolationTag)))
+	assert.Equal(t, 4, testutil.CollectAndCount(m.ImagePolicyViolations))
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// InfoVec série informativa (valor 1) com labels ou annotations Kubernetes
+// como labels Prometheus, no estilo do kube-state-metrics: o label
+// "team" vira "label_team". Como o conjunto de labels varia por objeto, a
+// família é registrada sem descrição prévia (unchecked).
+type InfoVec struct {
+	name   string
+	help   string
+	prefix string
+	keys   []string
+	allow  []string
+
+	mu   sync.Mutex
+	rows []infoRow
+}
+
+type infoRow struct {
+	values []string
+	meta   map[string]string
+}
+
+func newInfoVec(name, help, prefix string, allow []string, keys ...string) *InfoVec {
+	return &InfoVec{name: name, help: help, prefix: prefix, keys: keys, allow: allow}
+}
+
+// Enabled indica se algum label ou annotation do recurso é exposto.
+func (v *InfoVec) Enabled() bool { return len(v.allow) > 0 }
+
+// Set registra a série do objeto identificado por values (na ordem das chaves
+// da família) e retorna os itens de meta permitidos, ou nil se nenhum for.
+func (v *InfoVec) Set(meta map[string]string, values ...string) map[string]string {
+	if !v.Enabled() {
+		return nil
+	}
+	var kept map[string]string
+	for k, val := range meta {
+		if slices.Contains(v.allow, "*") || slices.Contains(v.allow, k) {
+			if kept == nil {
+				kept = map[string]string{}
+			}
+			kept[k] = val
+		}
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rows = append(v.rows, infoRow{values: values, meta: kept})
+	return kept
+}
+
+// Reset remove todas as séries.
+func (v *InfoVec) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rows = nil
+}
+
+// Describe implementa prometheus.Collector; não envia descrições.
+func (v *InfoVec) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implementa prometheus.Collector. Todas as séries da família usam a
+// união dos labels; objetos sem o label recebem valor vazio.
+func (v *InfoVec) Collect(ch chan<- prometheus.Metric) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	sanitized := make([]map[string]string, len(v.rows))
+	union := map[string]bool{}
+	for i, row := range v.rows {
+		sanitized[i] = v.sanitize(row.meta)
+		for n := range sanitized[i] {
+			union[n] = true
+		}
+	}
+	extra := make([]string, 0, len(union))
+	for n := range union {
+		extra = append(extra, n)
+	}
+	sort.Strings(extra)
+	desc := prometheus.NewDesc(v.name, v.help, append(slices.Clone(v.keys), extra...), nil)
+	for i, row := range v.rows {
+		values := slices.Clone(row.values)
+		for _, n := range extra {
+			values = append(values, sanitized[i][n])
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, values...)
+	}
+}
+
+// sanitize converte as chaves Kubernetes em nomes de label Prometheus. Chaves
+// que colidem após a conversão mantêm apenas a primeira em ordem alfabética.
+func (v *InfoVec) sanitize(meta map[string]string) map[string]string {
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		n := v.prefix + "_" + invalidLabelChars.ReplaceAllString(k, "_")
+		if _, ok := out[n]; !ok {
+			out[n] = meta[k]
+		}
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfoVec(t *testing.T) {
+	tests := []struct {
+		name         string
+		allow        []string
+		expectedKept map[string]string
+		expected     string
+	}{
+		{
+			name:         "should expose only allowed labels",
+			allow:        []string{"team"},
+			expectedKept: map[string]string{"team": "platform"},
+			expected: `
+k8s_node_labels{label_team="",node="n2"} 1
+k8s_node_labels{label_team="platform",node="n1"} 1
+`,
+		},
+		{
+			name:         "should expose all labels with wildcard",
+			allow:        []string{"*"},
+			expectedKept: map[string]string{"team": "platform", "topology.kubernetes.io/zone": "a"},
+			expected: `
+k8s_node_labels{label_team="",label_topology_kubernetes_io_zone="b",node="n2"} 1
+k8s_node_labels{label_team="platform",label_topology_kubernetes_io_zone="a",node="n1"} 1
+`,
+		},
+		{
+			name: "should not expose anything without allowlist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			v := newInfoVec("k8s_node_labels", "Labels", "label", tt.allow, "node")
+			reg := prometheus.NewPedanticRegistry()
+			require.NoError(t, reg.Register(v))
+
+			// Act
+			kept := v.Set(map[string]string{"team": "platform", "topology.kubernetes.io/zone": "a"}, "n1")
+			v.Set(map[string]string{"topology.kubernetes.io/zone": "b"}, "n2")
+
+			// Assert
+			assert.Equal(t, tt.expectedKept, kept)
+			expected := ""
+			if tt.expected != "" {
+				expected = "# HELP k8s_node_labels Labels\n# TYPE k8s_node_labels gauge" + tt.expected
+			}
+			assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "k8s_node_labels"))
+		})
+	}
+}
@@ -0,0 +1,414 @@
+package metrics
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"k8s-metrics-api/internal/k8s"
+)
+
+// PodCollector fases, restarts e recursos dos pods, por namespace e por
+// workload. ReplicaSets e Jobs são listados para resolver o workload; os Jobs
+// também fornecem k8s_job_labels e k8s_job_annotations.
+type PodCollector struct{}
+
+func (PodCollector) Name() string { return "pods" }
+
+func (PodCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		listRule("", "pods"),
+		listRule("apps", "replicasets"),
+		listRule("batch", "jobs"),
+	}
+}
+
+func (PodCollector) Describe() []string {
+	return []string{
+		"k8s_pods_total", "k8s_pod_status_phase", "k8s_container_restarts_total",
+		"k8s_namespace_cpu_requests_cores", "k8s_namespace_memory_requests_bytes",
+		"k8s_namespace_cpu_limits_cores", "k8s_namespace_memory_limits_bytes",
+		"k8s_workload_pods", "k8s_workload_pods_ready", "k8s_workload_restarts_total",
+		"k8s_workload_cpu_requests_cores", "k8s_workload_memory_requests_bytes",
+		"k8s_workload_cpu_limits_cores", "k8s_workload_memory_limits_bytes",
+		"k8s_image_policy_violations", "k8s_pod_compliance_violations",
+		"k8s_pods_by_qos_class", "k8s_pods_by_priority_class",
+		"k8s_node_pods_by_qos_class", "k8s_node_pods_by_priority_class",
+		"k8s_job_labels", "k8s_job_annotations",
+	}
+}
+
+// Collect agrega os pods página a página, sem manter a lista completa em
+// memória. Sem ReplicaSets ou Jobs, pods são atribuídos ao controlador direto.
+func (c PodCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	cs := src.Client.Clientset
+	scopes, opts := src.Filter.Scopes(), src.Filter.ListOptions()
+	replicaSets, err := List[appsv1.ReplicaSet](ctx, src, "replicasets", scopes, opts, func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return cs.AppsV1().ReplicaSets(ns).List(ctx, o)
+	})
+	if err != nil {
+		src.Log.WarnContext(ctx, "Erro ao listar ReplicaSets, workloads não resolvidos", "error", err)
+	}
+	jobs, err := List[batchv1.Job](ctx, src, "jobs", scopes, opts, func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return cs.BatchV1().Jobs(ns).List(ctx, o)
+	})
+	if err != nil {
+		src.Log.WarnContext(ctx, "Erro ao listar Jobs, workloads não resolvidos", "error", err)
+	}
+	owners := k8s.NewOwnerResolver(replicaSets, jobs)
+
+	s := newPodSummary(src.Images, src.Compliance)
+	for _, j := range jobs {
+		s.jobs = append(s.jobs, j.ObjectMeta)
+	}
+	err = src.Each(ctx, c.Name(), scopes, src.Filter.PodListOptions(), func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return cs.CoreV1().Pods(ns).List(ctx, o)
+	}, func(obj runtime.Object) {
+		p := obj.(*corev1.Pod)
+		s.add(p, owners.Resolve(p))
+	})
+	return s, err
+}
+
+// podSummary agregação dos pods de um cluster por namespace. O filtro de
+// namespaces é aplicado em Apply, quando o resultado de NamespaceCollector
+// já está disponível.
+type podSummary struct {
+	phases    map[string]map[string]int
+	resources map[string]resources
+	restarts  []containerRestarts
+	workloads map[workloadKey]*workloadSummary
+	// classes pods não concluídos por namespace, nó, QoS e PriorityClass.
+	classes map[classKey]int
+	// Inventário de imagens: images conta pods em execução por namespace e
+	// imagem, registries pods por namespace e registry (uma vez por pod),
+	// refs guarda cada imagem avaliada pela policy e violations conta pods
+	// fora da política por namespace e motivo.
+	policy     *ImagePolicy
+	images     map[imageKey]int
+	registries map[registryKey]int
+	refs       map[string]Image
+	violations map[violationKey]int
+	// compliance violações das regras de conformidade de pod e container.
+	compliance *complianceSummary
+	// jobs metadados dos Jobs listados, para o allowlist de metadados.
+	jobs []metav1.ObjectMeta
+}
+
+type imageKey struct{ namespace, image string }
+
+type classKey struct{ namespace, node, qos, priority string }
+
+type registryKey struct{ namespace, registry string }
+
+type violationKey struct{ namespace, reason string }
+
+type containerRestarts struct {
+	namespace, pod, container string
+	count                     float64
+}
+
+type workloadKey struct {
+	namespace string
+	k8s.Workload
+}
+
+type workloadSummary struct {
+	phases    map[string]int
+	ready     float64
+	restarts  float64
+	resources resources
+}
+
+func newPodSummary(policy *ImagePolicy, rules *ComplianceRules) *podSummary {
+	return &podSummary{
+		phases:     map[string]map[string]int{},
+		resources:  map[string]resources{},
+		workloads:  map[workloadKey]*workloadSummary{},
+		classes:    map[classKey]int{},
+		policy:     policy,
+		images:     map[imageKey]int{},
+		registries: map[registryKey]int{},
+		refs:       map[string]Image{},
+		violations: map[violationKey]int{},
+		compliance: newComplianceSummary(rules),
+	}
+}
+
+func (s *podSummary) add(p *corev1.Pod, wl k8s.Workload) {
+	phase := string(p.Status.Phase)
+	if s.phases[p.Namespace] == nil {
+		s.phases[p.Namespace] = map[string]int{}
+	}
+	s.phases[p.Namespace][phase]++
+
+	key := workloadKey{namespace: p.Namespace, Workload: wl}
+	w := s.workloads[key]
+	if w == nil {
+		w = &workloadSummary{phases: map[string]int{}}
+		s.workloads[key] = w
+	}
+	w.phases[phase]++
+	w.ready += boolToFloat(podReady(p))
+	for _, cs := range p.Status.ContainerStatuses {
+		s.restarts = append(s.restarts, containerRestarts{p.Namespace, p.Name, cs.Name, float64(cs.RestartCount)})
+		w.restarts += float64(cs.RestartCount)
+	}
+	r := podResources(p)
+	w.resources.add(r)
+	nsRes := s.resources[p.Namespace]
+	nsRes.add(r)
+	s.resources[p.Namespace] = nsRes
+	if p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed {
+		s.classes[classKey{p.Namespace, p.Spec.NodeName, podQOSClass(p), podPriorityClass(p)}]++
+	}
+	s.addImages(p)
+	s.compliance.add(p, wl)
+}
+
+// addImages contabiliza as imagens de containers e init containers de pods
+// ainda em execução, uma vez por pod.
+func (s *podSummary) addImages(p *corev1.Pod) {
+	if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+		return
+	}
+	seen := map[string]bool{}
+	registries := map[string]bool{}
+	reasons := map[string]bool{}
+	for _, c := range append(append([]corev1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...) {
+		if seen[c.Image] {
+			continue
+		}
+		seen[c.Image] = true
+		img, ok := s.refs[c.Image]
+		if !ok {
+			ref := parseImage(c.Image)
+			img = Image{Image: c.Image, ImageRef: ref, Latest: ref.Latest(), Violations: s.policy.Violations(ref)}
+			s.refs[c.Image] = img
+		}
+		s.images[imageKey{p.Namespace, c.Image}]++
+		registries[img.Registry] = true
+		for _, reason := range img.Violations {
+			reasons[reason] = true
+		}
+	}
+	for registry := range registries {
+		s.registries[registryKey{p.Namespace, registry}]++
+	}
+	for reason := range reasons {
+		s.violations[violationKey{p.Namespace, reason}]++
+	}
+}
+
+func (s *podSummary) Apply(snap *Snapshot, m *PrometheusMetrics) {
+	m.PodStatus.Reset()
+	m.ContainerRestarts.Reset()
+	for _, v := range []*GaugeVec{
+		m.WorkloadPods, m.WorkloadPodsReady, m.WorkloadRestarts,
+		m.WorkloadCPURequests, m.WorkloadMemoryRequests, m.WorkloadCPULimits, m.WorkloadMemoryLimits,
+		m.CPURequests, m.MemoryRequests, m.CPULimits, m.MemoryLimits,
+		m.ImagePolicyViolations,
+		m.PodsByQOSClass, m.PodsByPriorityClass, m.NodePodsByQOSClass, m.NodePodsByPriorityClass,
+	} {
+		v.Reset()
+	}
+
+	podCount := 0
+	for ns, phases := range s.phases {
+		if !snap.Selected(ns) {
+			continue
+		}
+		for phase, n := range phases {
+			podCount += n
+			m.PodStatus.WithLabelValues(ns, phase).Add(float64(n))
+			if snap.Allowed(ns) {
+				snap.PodPhases[phase] += n
+				snap.PodCount += n
+			}
+		}
+	}
+	m.PodCount.Set(float64(podCount))
+	for ns, r := range s.resources {
+		if !snap.Selected(ns) {
+			continue
+		}
+		m.CPURequests.WithLabelValues(ns).Add(r.cpuRequests)
+		m.MemoryRequests.WithLabelValues(ns).Add(r.memoryRequests)
+		m.CPULimits.WithLabelValues(ns).Add(r.cpuLimits)
+		m.MemoryLimits.WithLabelValues(ns).Add(r.memoryLimits)
+	}
+	for _, r := range s.restarts {
+		if snap.Selected(r.namespace) {
+			m.ContainerRestarts.WithLabelValues(r.namespace, r.pod, r.container).Add(r.count)
+		}
+	}
+	for key, w := range s.workloads {
+		if !snap.Selected(key.namespace) {
+			continue
+		}
+		labels := []string{key.namespace, key.Kind, key.Name}
+		for phase, n := range w.phases {
+			m.WorkloadPods.WithLabelValues(key.namespace, key.Kind, key.Name, phase).Add(float64(n))
+		}
+		m.WorkloadPodsReady.WithLabelValues(labels...).Add(w.ready)
+		m.WorkloadRestarts.WithLabelValues(labels...).Add(w.restarts)
+		m.WorkloadCPURequests.WithLabelValues(labels...).Add(w.resources.cpuRequests)
+		m.WorkloadMemoryRequests.WithLabelValues(labels...).Add(w.resources.memoryRequests)
+		m.WorkloadCPULimits.WithLabelValues(labels...).Add(w.resources.cpuLimits)
+		m.WorkloadMemoryLimits.WithLabelValues(labels...).Add(w.resources.memoryLimits)
+	}
+	s.applyClasses(snap, m)
+	snap.ImageViolations = map[string]int{}
+	for key, n := range s.violations {
+		if !snap.Selected(key.namespace) {
+			continue
+		}
+		m.ImagePolicyViolations.WithLabelValues(key.namespace, key.reason).Add(float64(n))
+		if snap.Allowed(key.namespace) {
+			snap.ImageViolations[key.reason] += n
+		}
+	}
+	snap.Images = s.inventory(snap)
+	snap.ImageRegistries = map[string]int{}
+	for key, n := range s.registries {
+		if snap.Selected(key.namespace) && snap.Allowed(key.namespace) {
+			snap.ImageRegistries[key.registry] += n
+		}
+	}
+	s.compliance.apply(snap, m.PodComplianceViolations)
+	snap.Jobs = applyMetadata(snap, m.JobLabels, m.JobAnnotations, s.jobs)
+}
+
+// applyClasses pods não concluídos por QoS e PriorityClass, por namespace e por
+// nó. Pods ainda não agendados não contam por nó.
+func (s *podSummary) applyClasses(snap *Snapshot, m *PrometheusMetrics) {
+	snap.PodQOSClasses, snap.PodPriorityClasses = map[string]int{}, map[string]int{}
+	snap.NamespacePodClasses, snap.NodePodClasses = map[string]PodClasses{}, map[string]PodClasses{}
+	for key, n := range s.classes {
+		if !snap.Selected(key.namespace) {
+			continue
+		}
+		m.PodsByQOSClass.WithLabelValues(key.namespace, key.qos).Add(float64(n))
+		m.PodsByPriorityClass.WithLabelValues(key.namespace, key.priority).Add(float64(n))
+		if key.node != "" {
+			m.NodePodsByQOSClass.WithLabelValues(key.node, key.qos).Add(float64(n))
+			m.NodePodsByPriorityClass.WithLabelValues(key.node, key.priority).Add(float64(n))
+		}
+		if !snap.Allowed(key.namespace) {
+			continue
+		}
+		snap.PodQOSClasses[key.qos] += n
+		snap.PodPriorityClasses[key.priority] += n
+		snap.NamespacePodClasses[key.namespace] = snap.NamespacePodClasses[key.namespace].add(key.qos, key.priority, n)
+		if key.node != "" {
+			snap.NodePodClasses[key.node] = snap.NodePodClasses[key.node].add(key.qos, key.priority, n)
+		}
+	}
+}
+
+// inventory imagens dos namespaces visíveis na resposta JSON, por nome.
+func (s *podSummary) inventory(snap *Snapshot) []Image {
+	byImage := map[string]*Image{}
+	for key, n := range s.images {
+		if !snap.Selected(key.namespace) || !snap.Allowed(key.namespace) {
+			continue
+		}
+		img := byImage[key.image]
+		if img == nil {
+			cp := s.refs[key.image]
+			cp.Namespaces = map[string]int{}
+			img = &cp
+			byImage[key.image] = img
+		}
+		img.Pods += n
+		img.Namespaces[key.namespace] += n
+	}
+	out := make([]Image, 0, len(byImage))
+	for _, name := range sortedKeys(byImage) {
+		out = append(out, *byImage[name])
+	}
+	return out
+}
+
+// resources soma de requests e limits dos containers de um pod.
+type resources struct {
+	cpuRequests, memoryRequests, cpuLimits, memoryLimits float64
+}
+
+func (r *resources) add(o resources) {
+	r.cpuRequests += o.cpuRequests
+	r.memoryRequests += o.memoryRequests
+	r.cpuLimits += o.cpuLimits
+	r.memoryLimits += o.memoryLimits
+}
+
+func podResources(p *corev1.Pod) resources {
+	var r resources
+	for _, c := range p.Spec.Containers {
+		if q, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+			r.cpuRequests += float64(q.MilliValue()) / 1000
+		}
+		if q, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
+			r.memoryRequests += float64(q.Value())
+		}
+		if q, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+			r.cpuLimits += float64(q.MilliValue()) / 1000
+		}
+		if q, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+			r.memoryLimits += float64(q.Value())
+		}
+	}
+	return r
+}
+
+// noPriorityClass label dos pods sem PriorityClass.
+const noPriorityClass = "none"
+
+func podPriorityClass(p *corev1.Pod) string {
+	if p.Spec.PriorityClassName == "" {
+		return noPriorityClass
+	}
+	return p.Spec.PriorityClassName
+}
+
+// podQOSClass classe de QoS do status ou, antes de o kubelet preenchê-la,
+// calculada pelos requests e limits de CPU e memória dos containers.
+func podQOSClass(p *corev1.Pod) string {
+	if p.Status.QOSClass != "" {
+		return string(p.Status.QOSClass)
+	}
+	guaranteed, bestEffort := true, true
+	for _, c := range append(append([]corev1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...) {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			req, lim := c.Resources.Requests[name], c.Resources.Limits[name]
+			if !req.IsZero() || !lim.IsZero() {
+				bestEffort = false
+			}
+			// Sem request, vale o limit.
+			if lim.IsZero() || (!req.IsZero() && req.Cmp(lim) != 0) {
+				guaranteed = false
+			}
+		}
+	}
+	switch {
+	case bestEffort:
+		return string(corev1.PodQOSBestEffort)
+	case guaranteed:
+		return string(corev1.PodQOSGuaranteed)
+	}
+	return string(corev1.PodQOSBurstable)
+}
+
+func podReady(p *corev1.Pod) bool {
+	for _, c := range p.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
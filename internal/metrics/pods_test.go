@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s-metrics-api/internal/k8s"
+)
+
+func TestPodQOSClass(t *testing.T) {
+	cpu := resource.MustParse("500m")
+	memory := resource.MustParse("128Mi")
+	tests := []struct {
+		name      string
+		status    corev1.PodQOSClass
+		resources corev1.ResourceRequirements
+		expected  string
+	}{
+		{name: "should prefer status", status: corev1.PodQOSBurstable, expected: "Burstable"},
+		{name: "should compute best effort", expected: "BestEffort"},
+		{
+			name:      "should compute guaranteed from limits only",
+			resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory}},
+			expected:  "Guaranteed",
+		},
+		{
+			name:      "should compute burstable when requests differ from limits",
+			resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}, Limits: corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory}},
+			expected:  "Burstable",
+		},
+		{
+			name:      "should compute burstable without memory limit",
+			resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: cpu}, Limits: corev1.ResourceList{corev1.ResourceCPU: cpu}},
+			expected:  "Burstable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p := &corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: tt.resources}}},
+				Status: corev1.PodStatus{QOSClass: tt.status},
+			}
+
+			// Act
+			qos := podQOSClass(p)
+
+			// Assert
+			assert.Equal(t, tt.expected, qos)
+		})
+	}
+}
+
+func TestPodCollectorClasses(t *testing.T) {
+	// Arrange
+	pod := func(ns, name, node, priority string, qos corev1.PodQOSClass, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec:       corev1.PodSpec{NodeName: node, PriorityClassName: priority},
+			Status:     corev1.PodStatus{Phase: phase, QOSClass: qos},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		pod("shop", "api-1", "node-a", "high", corev1.PodQOSGuaranteed, corev1.PodRunning),
+		pod("shop", "batch-1", "node-a", "", corev1.PodQOSBestEffort, corev1.PodRunning),
+		pod("shop", "batch-2", "", "", corev1.PodQOSBestEffort, corev1.PodPending),
+		pod("shop", "batch-3", "node-a", "", corev1.PodQOSBestEffort, corev1.PodPending),
+		pod("shop", "batch-0", "node-a", "", corev1.PodQOSBestEffort, corev1.PodSucceeded),
+		pod("ci", "runner-1", "node-a", "", corev1.PodQOSBestEffort, corev1.PodRunning),
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	src := &Source{Client: &k8s.Client{Clientset: clientset}, Metrics: m.Collector, Log: logger}
+	snap := NewSnapshot(nil, func(ns string) bool { return ns == "shop" })
+
+	// Act
+	res, err := PodCollector{}.Collect(context.Background(), src)
+	require.NoError(t, err)
+	res.Apply(snap, m)
+
+	// Assert
+	// Pods Pending contam; pods concluídos não.
+	assert.Equal(t, map[string]int{"Guaranteed": 1, "BestEffort": 3}, snap.PodQOSClasses)
+	assert.Equal(t, map[string]int{"high": 1, noPriorityClass: 3}, snap.PodPriorityClasses)
+	assert.Equal(t, map[string]PodClasses{"shop": {
+		QOSClasses:      map[string]int{"Guaranteed": 1, "BestEffort": 3},
+		PriorityClasses: map[string]int{"high": 1, noPriorityClass: 3},
+	}}, snap.NamespacePodClasses)
+	// Pods Pending já atribuídos ao nó contam nele; os não agendados, não.
+	assert.Equal(t, map[string]PodClasses{"node-a": {
+		QOSClasses:      map[string]int{"Guaranteed": 1, "BestEffort": 2},
+		PriorityClasses: map[string]int{"high": 1, noPriorityClass: 2},
+	}}, snap.NodePodClasses)
+	// As métricas consideram todos os namespaces.
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.PodsByQOSClass.WithLabelValues("shop", "BestEffort")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.PodsByPriorityClass.WithLabelValues("ci", noPriorityClass)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.NodePodsByQOSClass.WithLabelValues("node-a", "BestEffort")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.NodePodsByPriorityClass))
+}
@@ -4,6 +4,8 @@ import (
 	"log/slog"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s-metrics-api/internal/config"
 )
 
 // PrometheusMetrics guarda referências às métricas registradas.
@@ -13,38 +15,176 @@ type PrometheusMetrics struct {
 	DeploymentCount     prometheus.Gauge
 	ServiceCount        prometheus.Gauge
 	NamespaceCount      prometheus.Gauge
-	NodeReady           *prometheus.GaugeVec
-	PodStatus           *prometheus.GaugeVec
-	DeploymentDesired   *prometheus.GaugeVec
-	DeploymentAvailable *prometheus.GaugeVec
-	ContainerRestarts   *prometheus.GaugeVec
-	CPUAllocatable      *prometheus.GaugeVec
-	MemoryAllocatable   *prometheus.GaugeVec
-	CPURequests         *prometheus.GaugeVec
-	MemoryRequests      *prometheus.GaugeVec
-	CPULimits           *prometheus.GaugeVec
-	MemoryLimits        *prometheus.GaugeVec
+	NodeReady           *GaugeVec
+	PodStatus           *GaugeVec
+	DeploymentDesired   *GaugeVec
+	DeploymentAvailable *GaugeVec
+	ContainerRestarts   *GaugeVec
+	CPUAllocatable      *GaugeVec
+	MemoryAllocatable   *GaugeVec
+	CPURequests         *GaugeVec
+	MemoryRequests      *GaugeVec
+	CPULimits           *GaugeVec
+	MemoryLimits        *GaugeVec
+	// Agregados por workload (Deployment, StatefulSet, DaemonSet, CronJob...).
+	WorkloadPods           *GaugeVec
+	WorkloadPodsReady      *GaugeVec
+	WorkloadRestarts       *GaugeVec
+	WorkloadCPURequests    *GaugeVec
+	WorkloadMemoryRequests *GaugeVec
+	WorkloadCPULimits      *GaugeVec
+	WorkloadMemoryLimits   *GaugeVec
+	// ImagePolicyViolations pods fora da política de imagens, por motivo.
+	ImagePolicyViolations *GaugeVec
+	// Pods em execução por classe de QoS e PriorityClass, por namespace e por nó.
+	PodsByQOSClass          *GaugeVec
+	PodsByPriorityClass     *GaugeVec
+	NodePodsByQOSClass      *GaugeVec
+	NodePodsByPriorityClass *GaugeVec
+	// Violações das regras de conformidade por namespace e regra: pods (ou
+	// containers) e deployments.
+	PodComplianceViolations        *GaugeVec
+	DeploymentComplianceViolations *GaugeVec
+	// Exposição de services e Ingresses.
+	ServicesByType        *GaugeVec
+	LoadBalancerPending   *GaugeVec
+	ServiceEndpointsReady *GaugeVec
+	IngressCount          prometheus.Gauge
+	IngressHosts          *GaugeVec
+	IngressBackendMissing *GaugeVec
+	// Validade dos certificados TLS, em segundos Unix. Ficam fora do controle
+	// de cardinalidade: agregar séries de expiração não tem soma que faça
+	// sentido.
+	TLSSecretExpiry   *prometheus.GaugeVec
+	CertManagerExpiry *prometheus.GaugeVec
+	// Labels e annotations Kubernetes permitidos em MetadataAllowlistConfig.
+	NamespaceLabels        *InfoVec
+	NamespaceAnnotations   *InfoVec
+	NodeLabels             *InfoVec
+	NodeAnnotations        *InfoVec
+	DeploymentLabels       *InfoVec
+	DeploymentAnnotations  *InfoVec
+	StatefulSetLabels      *InfoVec
+	StatefulSetAnnotations *InfoVec
+	DaemonSetLabels        *InfoVec
+	DaemonSetAnnotations   *InfoVec
+	JobLabels              *InfoVec
+	JobAnnotations         *InfoVec
+	// Custom famílias dos recursos customizados, por nome do recurso.
+	Custom map[string]*CustomResourceMetrics
+	// Collector métricas da própria coleta.
+	Collector CollectorMetrics
+	// Series quantidade de séries emitidas por família na última coleta.
+	Series *prometheus.GaugeVec
+}
+
+// Option ajusta NewClusterMetrics.
+type Option func(*options)
+
+type options struct {
+	cardinality config.CardinalityConfig
+	metadata    config.MetadataAllowlistConfig
+	custom      []config.CustomResourceConfig
+}
+
+// WithCardinality aplica limites de cardinalidade às famílias com labels.
+// Use ValidateCardinality antes para rejeitar famílias ou labels desconhecidos.
+func WithCardinality(c config.CardinalityConfig) Option {
+	return func(o *options) { o.cardinality = c }
 }
 
-// NewPrometheusMetrics cria e registra métricas.
+// WithMetadataAllowlist expõe os labels e annotations permitidos em c.
+func WithMetadataAllowlist(c config.MetadataAllowlistConfig) Option {
+	return func(o *options) { o.metadata = c }
+}
+
+// WithCustomResources registra as famílias dos recursos customizados em crs.
+func WithCustomResources(crs []config.CustomResourceConfig) Option {
+	return func(o *options) { o.custom = crs }
+}
+
+// NewPrometheusMetrics cria e registra métricas do cluster padrão no registry global.
 func NewPrometheusMetrics(logger *slog.Logger) *PrometheusMetrics {
+	return NewClusterMetrics(prometheus.DefaultRegisterer, config.DefaultClusterName, logger)
+}
+
+// NewClusterMetrics cria e registra as métricas de um cluster. Todas as séries
+// recebem o label constante cluster, permitindo vários clusters no mesmo registry.
+func NewClusterMetrics(reg prometheus.Registerer, cluster string, logger *slog.Logger, opts ...Option) *PrometheusMetrics {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	reg = prometheus.WrapRegistererWith(prometheus.Labels{"cluster": cluster}, reg)
+	series := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "k8s_metrics_api_emitted_series",
+		Help: "Séries emitidas por família na última coleta",
+	}, []string{"family"})
+	vec := func(name, help string) *GaugeVec { return newGaugeVec(name, help, o.cardinality, series) }
 	m := &PrometheusMetrics{
+		Series:              series,
+		Collector:           newCollectorMetrics(),
 		NodeCount:           prometheus.NewGauge(prometheus.GaugeOpts{Name: "k8s_nodes_total", Help: "Total de nós"}),
 		PodCount:            prometheus.NewGauge(prometheus.GaugeOpts{Name: "k8s_pods_total", Help: "Total de pods"}),
 		DeploymentCount:     prometheus.NewGauge(prometheus.GaugeOpts{Name: "k8s_deployments_total", Help: "Total de deployments"}),
 		ServiceCount:        prometheus.NewGauge(prometheus.GaugeOpts{Name: "k8s_services_total", Help: "Total de services"}),
 		NamespaceCount:      prometheus.NewGauge(prometheus.GaugeOpts{Name: "k8s_namespaces_total", Help: "Total de namespaces"}),
-		NodeReady:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_node_status_ready", Help: "1 se Ready"}, []string{"node"}),
-		PodStatus:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_pod_status_phase", Help: "Status por fase"}, []string{"namespace", "phase"}),
-		DeploymentDesired:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_deployment_replicas_desired", Help: "Replicas desejadas"}, []string{"namespace", "deployment"}),
-		DeploymentAvailable: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_deployment_replicas_available", Help: "Replicas disponíveis"}, []string{"namespace", "deployment"}),
-		ContainerRestarts:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_container_restarts_total", Help: "Restart count"}, []string{"namespace", "pod", "container"}),
-		CPUAllocatable:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_node_cpu_allocatable_cores", Help: "CPU allocatable"}, []string{"node"}),
-		MemoryAllocatable:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_node_memory_allocatable_bytes", Help: "Memória allocatable"}, []string{"node"}),
-		CPURequests:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_namespace_cpu_requests_cores", Help: "Soma CPU requests"}, []string{"namespace"}),
-		MemoryRequests:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_namespace_memory_requests_bytes", Help: "Soma memória requests"}, []string{"namespace"}),
-		CPULimits:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_namespace_cpu_limits_cores", Help: "Soma CPU limits"}, []string{"namespace"}),
-		MemoryLimits:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "k8s_namespace_memory_limits_bytes", Help: "Soma memória limits"}, []string{"namespace"}),
+		NodeReady:           vec("k8s_node_status_ready", "1 se Ready"),
+		PodStatus:           vec("k8s_pod_status_phase", "Status por fase"),
+		DeploymentDesired:   vec("k8s_deployment_replicas_desired", "Replicas desejadas"),
+		DeploymentAvailable: vec("k8s_deployment_replicas_available", "Replicas disponíveis"),
+		ContainerRestarts:   vec("k8s_container_restarts_total", "Restart count"),
+		CPUAllocatable:      vec("k8s_node_cpu_allocatable_cores", "CPU allocatable"),
+		MemoryAllocatable:   vec("k8s_node_memory_allocatable_bytes", "Memória allocatable"),
+		CPURequests:         vec("k8s_namespace_cpu_requests_cores", "Soma CPU requests"),
+		MemoryRequests:      vec("k8s_namespace_memory_requests_bytes", "Soma memória requests"),
+		CPULimits:           vec("k8s_namespace_cpu_limits_cores", "Soma CPU limits"),
+		MemoryLimits:        vec("k8s_namespace_memory_limits_bytes", "Soma memória limits"),
+
+		WorkloadPods:           vec("k8s_workload_pods", "Pods do workload por fase"),
+		WorkloadPodsReady:      vec("k8s_workload_pods_ready", "Pods Ready do workload"),
+		WorkloadRestarts:       vec("k8s_workload_restarts_total", "Soma dos restarts dos containers do workload"),
+		WorkloadCPURequests:    vec("k8s_workload_cpu_requests_cores", "Soma CPU requests do workload"),
+		WorkloadMemoryRequests: vec("k8s_workload_memory_requests_bytes", "Soma memória requests do workload"),
+		WorkloadCPULimits:      vec("k8s_workload_cpu_limits_cores", "Soma CPU limits do workload"),
+		WorkloadMemoryLimits:   vec("k8s_workload_memory_limits_bytes", "Soma memória limits do workload"),
+
+		PodsByQOSClass:          vec("k8s_pods_by_qos_class", "Pods não concluídos (exceto Succeeded/Failed) por classe de QoS"),
+		PodsByPriorityClass:     vec("k8s_pods_by_priority_class", "Pods não concluídos (exceto Succeeded/Failed) por PriorityClass"),
+		NodePodsByQOSClass:      vec("k8s_node_pods_by_qos_class", "Pods não concluídos (exceto Succeeded/Failed) no nó por classe de QoS"),
+		NodePodsByPriorityClass: vec("k8s_node_pods_by_priority_class", "Pods não concluídos (exceto Succeeded/Failed) no nó por PriorityClass"),
+
+		ImagePolicyViolations: vec("k8s_image_policy_violations", "Pods com imagens fora da política, por motivo"),
+
+		PodComplianceViolations:        vec("k8s_pod_compliance_violations", "Containers ou pods fora das regras de conformidade, por regra"),
+		DeploymentComplianceViolations: vec("k8s_deployment_compliance_violations", "Deployments fora das regras de conformidade, por regra"),
+
+		ServicesByType:        vec("k8s_services_by_type", "Services por tipo"),
+		LoadBalancerPending:   vec("k8s_service_loadbalancer_pending", "1 se o LoadBalancer ainda não tem IP externo"),
+		ServiceEndpointsReady: vec("k8s_service_endpoints_ready", "Endpoints prontos do service"),
+		IngressCount:          prometheus.NewGauge(prometheus.GaugeOpts{Name: "k8s_ingresses_total", Help: "Total de ingresses"}),
+		IngressHosts:          vec("k8s_ingress_host_info", "Hosts do ingress, com uso de TLS"),
+		IngressBackendMissing: vec("k8s_ingress_backend_missing", "1 se o service de backend não existe"),
+
+		TLSSecretExpiry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "k8s_tls_secret_expiry_timestamp_seconds", Help: "Expiração do certificado do Secret TLS",
+		}, []string{"namespace", "secret", "subject"}),
+		CertManagerExpiry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "k8s_certmanager_certificate_expiry_timestamp_seconds", Help: "Expiração do Certificate do cert-manager",
+		}, []string{"namespace", "certificate"}),
+
+		NamespaceLabels:        newInfoVec("k8s_namespace_labels", "Labels Kubernetes do namespace", "label", o.metadata.Labels[config.ResourceNamespaces], "namespace"),
+		NamespaceAnnotations:   newInfoVec("k8s_namespace_annotations", "Annotations Kubernetes do namespace", "annotation", o.metadata.Annotations[config.ResourceNamespaces], "namespace"),
+		NodeLabels:             newInfoVec("k8s_node_labels", "Labels Kubernetes do nó", "label", o.metadata.Labels[config.ResourceNodes], "node"),
+		NodeAnnotations:        newInfoVec("k8s_node_annotations", "Annotations Kubernetes do nó", "annotation", o.metadata.Annotations[config.ResourceNodes], "node"),
+		DeploymentLabels:       newInfoVec("k8s_deployment_labels", "Labels Kubernetes do deployment", "label", o.metadata.Labels[config.ResourceDeployments], "namespace", "deployment"),
+		DeploymentAnnotations:  newInfoVec("k8s_deployment_annotations", "Annotations Kubernetes do deployment", "annotation", o.metadata.Annotations[config.ResourceDeployments], "namespace", "deployment"),
+		StatefulSetLabels:      newInfoVec("k8s_statefulset_labels", "Labels Kubernetes do statefulset", "label", o.metadata.Labels[config.ResourceStatefulSets], "namespace", "statefulset"),
+		StatefulSetAnnotations: newInfoVec("k8s_statefulset_annotations", "Annotations Kubernetes do statefulset", "annotation", o.metadata.Annotations[config.ResourceStatefulSets], "namespace", "statefulset"),
+		DaemonSetLabels:        newInfoVec("k8s_daemonset_labels", "Labels Kubernetes do daemonset", "label", o.metadata.Labels[config.ResourceDaemonSets], "namespace", "daemonset"),
+		DaemonSetAnnotations:   newInfoVec("k8s_daemonset_annotations", "Annotations Kubernetes do daemonset", "annotation", o.metadata.Annotations[config.ResourceDaemonSets], "namespace", "daemonset"),
+		JobLabels:              newInfoVec("k8s_job_labels", "Labels Kubernetes do job", "label", o.metadata.Labels[config.ResourceJobs], "namespace", "job"),
+		JobAnnotations:         newInfoVec("k8s_job_annotations", "Annotations Kubernetes do job", "annotation", o.metadata.Annotations[config.ResourceJobs], "namespace", "job"),
 	}
 
 	collectors := []prometheus.Collector{
@@ -52,10 +192,34 @@ func NewPrometheusMetrics(logger *slog.Logger) *PrometheusMetrics {
 		m.NodeReady, m.PodStatus, m.DeploymentDesired, m.DeploymentAvailable,
 		m.ContainerRestarts, m.CPUAllocatable, m.MemoryAllocatable,
 		m.CPURequests, m.MemoryRequests, m.CPULimits, m.MemoryLimits,
+		m.WorkloadPods, m.WorkloadPodsReady, m.WorkloadRestarts,
+		m.WorkloadCPURequests, m.WorkloadMemoryRequests, m.WorkloadCPULimits, m.WorkloadMemoryLimits,
+		m.PodsByQOSClass, m.PodsByPriorityClass, m.NodePodsByQOSClass, m.NodePodsByPriorityClass,
+		m.ImagePolicyViolations, m.PodComplianceViolations, m.DeploymentComplianceViolations,
+		m.ServicesByType, m.LoadBalancerPending, m.ServiceEndpointsReady,
+		m.IngressCount, m.IngressHosts, m.IngressBackendMissing,
+		m.TLSSecretExpiry, m.CertManagerExpiry,
+		m.Series,
+	}
+	collectors = append(collectors, m.Collector.collectors()...)
+	m.Custom = make(map[string]*CustomResourceMetrics, len(o.custom))
+	for _, cr := range o.custom {
+		m.Custom[cr.Name] = newCustomResourceMetrics(cr)
+		collectors = append(collectors, m.Custom[cr.Name].collectors()...)
+	}
+	for _, info := range []*InfoVec{
+		m.NamespaceLabels, m.NamespaceAnnotations, m.NodeLabels, m.NodeAnnotations,
+		m.DeploymentLabels, m.DeploymentAnnotations,
+		m.StatefulSetLabels, m.StatefulSetAnnotations, m.DaemonSetLabels, m.DaemonSetAnnotations,
+		m.JobLabels, m.JobAnnotations,
+	} {
+		if info.Enabled() {
+			collectors = append(collectors, info)
+		}
 	}
 	for _, c := range collectors {
-		_ = prometheus.Register(c) // ignora AlreadyRegistered
+		_ = reg.Register(c) // ignora AlreadyRegistered
 	}
-	logger.Info("Métricas Prometheus registradas")
+	logger.Info("Métricas Prometheus registradas", "cluster", cluster)
 	return m
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -113,3 +114,33 @@ func TestPrometheusMetricsRegistry(t *testing.T) {
 		})
 	}
 }
+
+func TestNewClusterMetricsAddsClusterLabel(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	reg := prometheus.NewRegistry()
+	east := NewClusterMetrics(reg, "east", logger)
+	west := NewClusterMetrics(reg, "west", logger)
+
+	// Act
+	east.NodeCount.Set(2)
+	west.NodeCount.Set(3)
+	families, err := reg.Gather()
+
+	// Assert
+	require.NoError(t, err)
+	values := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != "k8s_nodes_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "cluster" {
+					values[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"east": 2, "west": 3}, values)
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// VerbList verbo das chamadas List ao API server.
+const VerbList = "list"
+
+// CollectorMetrics métricas da própria coleta de um cluster.
+type CollectorMetrics struct {
+	// Duration duração da listagem de cada tipo de recurso.
+	Duration *prometheus.HistogramVec
+	// APIErrors chamadas ao API server que falharam, por verbo e recurso.
+	APIErrors *prometheus.CounterVec
+	// Objects objetos processados por tipo de recurso.
+	Objects *prometheus.CounterVec
+	// LastSuccess horário da última coleta sem erros, em segundos Unix.
+	LastSuccess prometheus.Gauge
+	// Up 1 se a última listagem do recurso teve sucesso, 0 se falhou.
+	Up *prometheus.GaugeVec
+}
+
+func newCollectorMetrics() CollectorMetrics {
+	return CollectorMetrics{
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "k8s_metrics_api_collection_duration_seconds",
+			Help:    "Duração da listagem por tipo de recurso",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"resource"}),
+		APIErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "k8s_metrics_api_kube_api_errors_total",
+			Help: "Chamadas ao API server com erro",
+		}, []string{"verb", "resource"}),
+		Objects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "k8s_metrics_api_objects_processed_total",
+			Help: "Objetos processados por tipo de recurso",
+		}, []string{"resource"}),
+		LastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "k8s_metrics_api_last_successful_collection_timestamp_seconds",
+			Help: "Horário da última coleta sem erros",
+		}),
+		Up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "k8s_collector_up",
+			Help: "1 se a última listagem do recurso teve sucesso, 0 se falhou",
+		}, []string{"resource"}),
+	}
+}
+
+func (c CollectorMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.Duration, c.APIErrors, c.Objects, c.LastSuccess, c.Up}
+}
+
+// ObserveList registra uma listagem de resource iniciada em start que
+// retornou n objetos ou falhou com err.
+func (c CollectorMetrics) ObserveList(resource string, start time.Time, n int, err error) {
+	c.Duration.WithLabelValues(resource).Observe(time.Since(start).Seconds())
+	if err != nil {
+		c.APIErrors.WithLabelValues(VerbList, resource).Inc()
+		c.Up.WithLabelValues(resource).Set(0)
+		return
+	}
+	c.Up.WithLabelValues(resource).Set(1)
+	c.Objects.WithLabelValues(resource).Add(float64(n))
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectorMetricsObserveList(t *testing.T) {
+	tests := []struct {
+		name            string
+		n               int
+		err             error
+		expectedObjects float64
+		expectedErrors  float64
+		expectedUp      float64
+	}{
+		{name: "should count processed objects", n: 3, expectedObjects: 3, expectedUp: 1},
+		{name: "should count errors", err: errors.New("forbidden"), expectedErrors: 1, expectedUp: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c := newCollectorMetrics()
+
+			// Act
+			c.ObserveList("pods", time.Now(), tt.n, tt.err)
+
+			// Assert
+			assert.Equal(t, 1, testutil.CollectAndCount(c.Duration))
+			assert.Equal(t, tt.expectedObjects, testutil.ToFloat64(c.Objects.WithLabelValues("pods")))
+			assert.Equal(t, tt.expectedErrors, testutil.ToFloat64(c.APIErrors.WithLabelValues(VerbList, "pods")))
+			assert.Equal(t, tt.expectedUp, testutil.ToFloat64(c.Up.WithLabelValues("pods")))
+		})
+	}
+}
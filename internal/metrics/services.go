@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ServiceCollector services por tipo, LoadBalancers sem IP externo e
+// endpoints prontos de cada service, pelas EndpointSlices.
+type ServiceCollector struct{}
+
+func (ServiceCollector) Name() string { return "services" }
+
+func (ServiceCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		listRule("", "services"),
+		listRule("discovery.k8s.io", "endpointslices"),
+	}
+}
+
+func (ServiceCollector) Describe() []string {
+	return []string{
+		"k8s_services_total", "k8s_services_by_type",
+		"k8s_service_loadbalancer_pending", "k8s_service_endpoints_ready",
+	}
+}
+
+// Collect lista as EndpointSlices antes dos services. Sem elas, apenas os
+// endpoints deixam de ser informados.
+func (c ServiceCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	cs := src.Client.Clientset
+	scopes, opts := src.Filter.Scopes(), src.Filter.ListOptions()
+	res := &serviceResult{}
+	ready := map[string]map[string]bool{}
+	err := src.Each(ctx, "endpointslices", scopes, opts, func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return cs.DiscoveryV1().EndpointSlices(ns).List(ctx, o)
+	}, func(obj runtime.Object) {
+		slice := obj.(*discoveryv1.EndpointSlice)
+		svc := slice.Labels[discoveryv1.LabelServiceName]
+		if svc == "" {
+			return
+		}
+		key := slice.Namespace + "/" + svc
+		if ready[key] == nil {
+			ready[key] = map[string]bool{}
+		}
+		for _, ep := range slice.Endpoints {
+			if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				continue
+			}
+			ready[key][endpointKey(ep)] = true
+		}
+	})
+	if err != nil {
+		src.Log.WarnContext(ctx, "Erro ao listar EndpointSlices, endpoints não informados", "error", err)
+	} else {
+		res.ready = make(map[string]int, len(ready))
+		for key, eps := range ready {
+			res.ready[key] = len(eps)
+		}
+	}
+	res.services, err = List[corev1.Service](ctx, src, c.Name(), scopes, opts, func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return cs.CoreV1().Services(ns).List(ctx, o)
+	})
+	return res, err
+}
+
+// endpointKey identifica o endpoint entre slices de famílias de endereço
+// diferentes (dual-stack): pelo objeto de destino ou, sem ele, pelo endereço.
+func endpointKey(ep discoveryv1.Endpoint) string {
+	if ref := ep.TargetRef; ref != nil {
+		return ref.Kind + "/" + ref.Namespace + "/" + ref.Name
+	}
+	if len(ep.Addresses) > 0 {
+		return ep.Addresses[0]
+	}
+	return ""
+}
+
+type serviceResult struct {
+	services []corev1.Service
+	// ready endpoints prontos por "namespace/nome"; nil sem EndpointSlices.
+	ready map[string]int
+}
+
+func (r *serviceResult) Apply(s *Snapshot, m *PrometheusMetrics) {
+	m.ServicesByType.Reset()
+	m.LoadBalancerPending.Reset()
+	m.ServiceEndpointsReady.Reset()
+	count := 0
+	byType := map[string]int{}
+	s.services = map[string]bool{}
+	for _, svc := range r.services {
+		if !s.Selected(svc.Namespace) {
+			continue
+		}
+		count++
+		key := svc.Namespace + "/" + svc.Name
+		s.services[key] = true
+		typ := string(svc.Spec.Type)
+		if typ == "" {
+			typ = string(corev1.ServiceTypeClusterIP)
+		}
+		m.ServicesByType.WithLabelValues(svc.Namespace, typ).Add(1)
+		pending := svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(svc.Status.LoadBalancer.Ingress) == 0
+		if pending {
+			m.LoadBalancerPending.WithLabelValues(svc.Namespace, svc.Name).Add(1)
+		}
+		// ExternalName não tem endpoints.
+		noEndpoints := false
+		if r.ready != nil && svc.Spec.Type != corev1.ServiceTypeExternalName {
+			m.ServiceEndpointsReady.WithLabelValues(svc.Namespace, svc.Name).Add(float64(r.ready[key]))
+			noEndpoints = r.ready[key] == 0
+		}
+		if !s.Allowed(svc.Namespace) {
+			continue
+		}
+		s.ServiceCount++
+		byType[typ]++
+		if pending {
+			s.PendingLoadBalancers = append(s.PendingLoadBalancers, key)
+		}
+		if noEndpoints {
+			s.ServicesWithoutEndpoints = append(s.ServicesWithoutEndpoints, key)
+		}
+	}
+	m.ServiceCount.Set(float64(count))
+	s.ServicesByType = byType
+	sort.Strings(s.PendingLoadBalancers)
+	sort.Strings(s.ServicesWithoutEndpoints)
+}
+
+// Ingress inventário de um Ingress na resposta JSON.
+type Ingress struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Class     string   `json:"class,omitempty"`
+	Hosts     []string `json:"hosts,omitempty"`
+	// TLSHosts hosts cobertos pela seção tls.
+	TLSHosts []string `json:"tlsHosts,omitempty"`
+	// Backends services referenciados pelas regras e pelo defaultBackend.
+	Backends []string `json:"backends,omitempty"`
+	// MissingBackends backends inexistentes no namespace; omitido quando o
+	// collector services não está habilitado.
+	MissingBackends []string `json:"missingBackends,omitempty"`
+}
+
+// IngressCollector hosts, TLS e backends dos Ingresses. Backends são
+// conferidos contra os services aplicados antes na mesma coleta, por isso
+// vem depois de ServiceCollector no Registry.
+type IngressCollector struct{}
+
+func (IngressCollector) Name() string { return "ingresses" }
+
+func (IngressCollector) Rules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{listRule("networking.k8s.io", "ingresses")}
+}
+
+func (IngressCollector) Describe() []string {
+	return []string{"k8s_ingresses_total", "k8s_ingress_host_info", "k8s_ingress_backend_missing"}
+}
+
+func (c IngressCollector) Collect(ctx context.Context, src *Source) (Result, error) {
+	var res ingressResult
+	err := src.Each(ctx, c.Name(), src.Filter.Scopes(), src.Filter.ListOptions(), func(ctx context.Context, ns string, o metav1.ListOptions) (runtime.Object, error) {
+		return src.Client.Clientset.NetworkingV1().Ingresses(ns).List(ctx, o)
+	}, func(obj runtime.Object) {
+		res = append(res, ingressInventory(obj.(*networkingv1.Ingress)))
+	})
+	return res, err
+}
+
+func ingressInventory(ing *networkingv1.Ingress) Ingress {
+	out := Ingress{Namespace: ing.Namespace, Name: ing.Name}
+	if ing.Spec.IngressClassName != nil {
+		out.Class = *ing.Spec.IngressClassName
+	}
+	hosts, tls, backends := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	if b := ing.Spec.DefaultBackend; b != nil && b.Service != nil {
+		backends[b.Service.Name] = true
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host != "" {
+			hosts[rule.Host] = true
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, p := range rule.HTTP.Paths {
+			if p.Backend.Service != nil {
+				backends[p.Backend.Service.Name] = true
+			}
+		}
+	}
+	for _, t := range ing.Spec.TLS {
+		for _, h := range t.Hosts {
+			tls[h] = true
+		}
+	}
+	out.Hosts, out.TLSHosts, out.Backends = sortedSet(hosts), sortedSet(tls), sortedSet(backends)
+	return out
+}
+
+type ingressResult []Ingress
+
+func (r ingressResult) Apply(s *Snapshot, m *PrometheusMetrics) {
+	m.IngressHosts.Reset()
+	m.IngressBackendMissing.Reset()
+	count := 0
+	var inventory []Ingress
+	for _, ing := range r {
+		if !s.Selected(ing.Namespace) {
+			continue
+		}
+		count++
+		for _, h := range ing.Hosts {
+			m.IngressHosts.WithLabelValues(ing.Namespace, ing.Name, h, boolLabel(contains(ing.TLSHosts, h))).Add(1)
+		}
+		ing.MissingBackends = nil
+		if s.services != nil {
+			for _, b := range ing.Backends {
+				if !s.services[ing.Namespace+"/"+b] {
+					ing.MissingBackends = append(ing.MissingBackends, b)
+					m.IngressBackendMissing.WithLabelValues(ing.Namespace, ing.Name, b).Add(1)
+				}
+			}
+		}
+		if s.Allowed(ing.Namespace) {
+			inventory = append(inventory, ing)
+		}
+	}
+	m.IngressCount.Set(float64(count))
+	s.Ingresses = inventory
+}
+
+func sortedSet(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	return sortedKeys(m)
+}
+
+func boolLabel(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s-metrics-api/internal/k8s"
+)
+
+func service(ns, name string, typ corev1.ServiceType, lbIPs ...string) *corev1.Service {
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}, Spec: corev1.ServiceSpec{Type: typ}}
+	for _, ip := range lbIPs {
+		svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+	}
+	return svc
+}
+
+// endpointSlice slice do service com um endpoint por pod; ready indica a
+// condição de cada um.
+func endpointSlice(ns, name, svc string, addressType discoveryv1.AddressType, ready map[string]bool) *discoveryv1.EndpointSlice {
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: ns, Name: name, Labels: map[string]string{discoveryv1.LabelServiceName: svc}},
+		AddressType: addressType,
+	}
+	for pod, ok := range ready {
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{pod + "-" + string(addressType)},
+			Conditions: discoveryv1.EndpointConditions{Ready: &ok},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Namespace: ns, Name: pod},
+		})
+	}
+	return slice
+}
+
+func TestServiceCollector(t *testing.T) {
+	// Arrange
+	clientset := fake.NewSimpleClientset(
+		service("shop", "api", corev1.ServiceTypeClusterIP),
+		service("shop", "web", corev1.ServiceTypeLoadBalancer),
+		service("shop", "edge", corev1.ServiceTypeLoadBalancer, "203.0.113.10"),
+		service("shop", "db", corev1.ServiceTypeExternalName),
+		service("ci", "runner", corev1.ServiceTypeNodePort),
+		// Dual-stack: o mesmo pod nas slices IPv4 e IPv6.
+		endpointSlice("shop", "api-v4", "api", discoveryv1.AddressTypeIPv4, map[string]bool{"api-1": true, "api-2": false}),
+		endpointSlice("shop", "api-v6", "api", discoveryv1.AddressTypeIPv6, map[string]bool{"api-1": true}),
+		endpointSlice("shop", "web-v4", "web", discoveryv1.AddressTypeIPv4, map[string]bool{"web-1": false}),
+	)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	src := &Source{Client: &k8s.Client{Clientset: clientset}, Metrics: m.Collector, Log: logger}
+	snap := NewSnapshot(nil, func(ns string) bool { return ns == "shop" })
+
+	// Act
+	res, err := ServiceCollector{}.Collect(context.Background(), src)
+	require.NoError(t, err)
+	res.Apply(snap, m)
+
+	// Assert
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.ServiceCount))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.ServicesByType.WithLabelValues("shop", "LoadBalancer")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.ServicesByType.WithLabelValues("ci", "NodePort")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.LoadBalancerPending))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.ServiceEndpointsReady.WithLabelValues("shop", "api")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.ServiceEndpointsReady.WithLabelValues("shop", "web")))
+	// ExternalName não gera série de endpoints.
+	assert.Equal(t, 4, testutil.CollectAndCount(m.ServiceEndpointsReady))
+	assert.Equal(t, 4, snap.ServiceCount)
+	assert.Equal(t, map[string]int{"ClusterIP": 1, "LoadBalancer": 2, "ExternalName": 1}, snap.ServicesByType)
+	assert.Equal(t, []string{"shop/web"}, snap.PendingLoadBalancers)
+	assert.Equal(t, []string{"shop/edge", "shop/web"}, snap.ServicesWithoutEndpoints)
+}
+
+func TestServiceCollectorWithoutEndpointSlices(t *testing.T) {
+	// Arrange
+	clientset := fake.NewSimpleClientset(service("shop", "api", corev1.ServiceTypeClusterIP))
+	clientset.PrependReactor("list", "endpointslices", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("endpointslices is forbidden")
+	})
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+	src := &Source{Client: &k8s.Client{Clientset: clientset}, Metrics: m.Collector, Log: logger}
+	snap := NewSnapshot(nil, nil)
+
+	// Act
+	res, err := ServiceCollector{}.Collect(context.Background(), src)
+	require.NoError(t, err)
+	res.Apply(snap, m)
+
+	// Assert
+	assert.Equal(t, 1, snap.ServiceCount)
+	assert.Empty(t, snap.ServicesWithoutEndpoints)
+	assert.Equal(t, 0, testutil.CollectAndCount(m.ServiceEndpointsReady))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.Collector.Up.WithLabelValues("endpointslices")))
+}
+
+func ingress(ns, name string, tlsHosts []string, rules map[string]string, defaultBackend string) *networkingv1.Ingress {
+	ing := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}}
+	class := "nginx"
+	ing.Spec.IngressClassName = &class
+	if defaultBackend != "" {
+		ing.Spec.DefaultBackend = &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: defaultBackend}}
+	}
+	for host, svc := range rules {
+		ing.Spec.Rules = append(ing.Spec.Rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{
+				Path:    "/",
+				Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: svc}},
+			}}}},
+		})
+	}
+	if len(tlsHosts) > 0 {
+		ing.Spec.TLS = []networkingv1.IngressTLS{{Hosts: tlsHosts, SecretName: name + "-tls"}}
+	}
+	return ing
+}
+
+func TestIngressCollector(t *testing.T) {
+	tests := []struct {
+		name            string
+		withServices    bool
+		expectedMissing []string
+	}{
+		{name: "should report backends without service", withServices: true, expectedMissing: []string{"legacy"}},
+		{name: "should skip backend check without services collector", withServices: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			clientset := fake.NewSimpleClientset(
+				service("shop", "api", corev1.ServiceTypeClusterIP),
+				service("shop", "web", corev1.ServiceTypeClusterIP),
+				ingress("shop", "public", []string{"shop.example.com"}, map[string]string{"shop.example.com": "web", "api.example.com": "api"}, "legacy"),
+			)
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			m := NewClusterMetrics(prometheus.NewRegistry(), "default", logger)
+			src := &Source{Client: &k8s.Client{Clientset: clientset}, Metrics: m.Collector, Log: logger}
+			snap := NewSnapshot(nil, nil)
+			if tt.withServices {
+				res, err := ServiceCollector{}.Collect(context.Background(), src)
+				require.NoError(t, err)
+				res.Apply(snap, m)
+			}
+
+			// Act
+			res, err := IngressCollector{}.Collect(context.Background(), src)
+			require.NoError(t, err)
+			res.Apply(snap, m)
+
+			// Assert
+			assert.Equal(t, []Ingress{{
+				Namespace:       "shop",
+				Name:            "public",
+				Class:           "nginx",
+				Hosts:           []string{"api.example.com", "shop.example.com"},
+				TLSHosts:        []string{"shop.example.com"},
+				Backends:        []string{"api", "legacy", "web"},
+				MissingBackends: tt.expectedMissing,
+			}}, snap.Ingresses)
+			assert.Equal(t, 1.0, testutil.ToFloat64(m.IngressCount))
+			assert.Equal(t, 1.0, testutil.ToFloat64(m.IngressHosts.WithLabelValues("shop", "public", "shop.example.com", "true")))
+			assert.Equal(t, 1.0, testutil.ToFloat64(m.IngressHosts.WithLabelValues("shop", "public", "api.example.com", "false")))
+			assert.Equal(t, len(tt.expectedMissing), testutil.CollectAndCount(m.IngressBackendMissing))
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// AuditMiddleware registra cada requisição que passou pela autenticação
+// (aceita ou negada) no logger de auditoria, separado do log da aplicação.
+func AuditMiddleware(audit *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx, st := withState(r.Context())
+			rw := &respWriter{ResponseWriter: w, status: 200}
+			r = r.WithContext(ctx)
+			next.ServeHTTP(rw, r)
+			if !st.authChecked {
+				return
+			}
+			attrs := []slog.Attr{
+				slog.Bool("authenticated", st.identity != nil),
+				slog.String("clientIP", ClientIP(r)),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("query", r.URL.RawQuery),
+				slog.Int("status", rw.status),
+				slog.Float64("latencyMs", float64(time.Since(start).Microseconds())/1000),
+				slog.String("userAgent", r.UserAgent()),
+			}
+			if st.identity != nil {
+				attrs = append(attrs, slog.String("identity", st.identity.Name), slog.String("authMethod", st.identity.Method))
+			}
+			audit.LogAttrs(ctx, slog.LevelInfo, "access", attrs...)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s-metrics-api/internal/config"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	trusted, err := config.ParseCIDRs("172.20.0.0/16,10.0.0.1")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		expectedIP   string
+	}{
+		{name: "should use remote addr without header", remoteAddr: "192.168.1.5:1234", expectedIP: "192.168.1.5"},
+		{name: "should ignore header from untrusted peer", remoteAddr: "192.168.1.5:1234", forwardedFor: "1.2.3.4", expectedIP: "192.168.1.5"},
+		{name: "should honor header from trusted proxy", remoteAddr: "172.20.0.10:1234", forwardedFor: "1.2.3.4", expectedIP: "1.2.3.4"},
+		{name: "should skip trusted hops", remoteAddr: "172.20.0.10:1234", forwardedFor: "6.6.6.6, 1.2.3.4, 10.0.0.1", expectedIP: "1.2.3.4"},
+		{name: "should stop at invalid hop", remoteAddr: "172.20.0.10:1234", forwardedFor: "garbage", expectedIP: "172.20.0.10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var got string
+			handler := RealIPMiddleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+
+			// Act
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			assert.Equal(t, tt.expectedIP, got)
+		})
+	}
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		path             string
+		headerToken      string
+		expectEntry      bool
+		expectedStatus   float64
+		expectedIdentity any
+	}{
+		{name: "should audit authenticated access", path: "/metrics?ns=default", headerToken: "Bearer " + validToken, expectEntry: true, expectedStatus: 200, expectedIdentity: "default"},
+		{name: "should audit rejected access", path: "/metrics", headerToken: "Bearer wrong", expectEntry: true, expectedStatus: 401, expectedIdentity: nil},
+		{name: "should skip unauthenticated routes", path: "/healthz", expectEntry: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			mux := http.NewServeMux()
+			mux.HandleFunc("/metrics", AuthMiddleware(validToken, logger)(func(w http.ResponseWriter, r *http.Request) {}))
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+			handler := AuditMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)))(mux)
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.headerToken != "" {
+				req.Header.Set("Authorization", tt.headerToken)
+			}
+
+			// Act
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			if !tt.expectEntry {
+				assert.Empty(t, buf.String())
+				return
+			}
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			assert.Equal(t, tt.expectedStatus, entry["status"])
+			assert.Equal(t, tt.expectedIdentity, entry["identity"])
+			assert.Equal(t, "/metrics", entry["path"])
+			assert.Equal(t, "192.0.2.1", entry["clientIP"])
+			assert.Contains(t, entry, "latencyMs")
+		})
+	}
+}
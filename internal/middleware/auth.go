@@ -1,23 +1,130 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
+
+	"k8s-metrics-api/internal/config"
 )
 
-// AuthMiddleware valida Bearer token.
-func AuthMiddleware(expectedToken string, logger *slog.Logger) func(http.HandlerFunc) http.HandlerFunc {
+// TokenLookup resolve um token secreto em um token nomeado.
+type TokenLookup interface {
+	Lookup(secret string) (config.Token, bool)
+}
+
+// Authenticator valida credenciais e exige escopos por rota.
+type Authenticator struct {
+	tokens        TokenLookup
+	jwt           *JWTValidator
+	schemes       map[string]bool
+	basicUsername string
+	log           *slog.Logger
+}
+
+// AuthOption configura o Authenticator.
+type AuthOption func(*Authenticator)
+
+// WithJWT habilita a validação de JWTs OIDC como Bearer token.
+func WithJWT(v *JWTValidator) AuthOption {
+	return func(a *Authenticator) { a.jwt = v }
+}
+
+// WithSchemes define os esquemas aceitos no header Authorization
+// (config.AuthSchemeBearer, config.AuthSchemeBasic). O padrão é apenas Bearer.
+func WithSchemes(schemes ...string) AuthOption {
+	return func(a *Authenticator) {
+		a.schemes = map[string]bool{}
+		for _, s := range schemes {
+			a.schemes[strings.ToLower(s)] = true
+		}
+	}
+}
+
+// WithBasicUsername exige o usuário informado em credenciais Basic.
+// Vazio aceita qualquer usuário; o token é sempre validado como senha.
+func WithBasicUsername(username string) AuthOption {
+	return func(a *Authenticator) { a.basicUsername = username }
+}
+
+// NewAuthenticator cria Authenticator.
+func NewAuthenticator(tokens TokenLookup, logger *slog.Logger, opts ...AuthOption) *Authenticator {
+	a := &Authenticator{tokens: tokens, schemes: map[string]bool{config.AuthSchemeBearer: true}, log: logger}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+// Require valida as credenciais e exige o escopo informado ("" aceita qualquer token válido).
+func (a *Authenticator) Require(scope string) func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			auth := r.Header.Get("Authorization")
-			parts := strings.Split(auth, " ")
-			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" || parts[1] != expectedToken {
-				logger.Warn("Acesso não autorizado", "path", r.URL.Path)
+			markAuthChecked(r.Context())
+			id, err := a.authenticate(r)
+			if errors.Is(err, ErrJWTNoGroups) {
+				a.log.WarnContext(r.Context(), "Acesso negado", "path", r.URL.Path, "error", err)
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				a.log.WarnContext(r.Context(), "Acesso não autorizado", "path", r.URL.Path, "error", err)
+				if a.schemes[config.AuthSchemeBasic] {
+					w.Header().Set("WWW-Authenticate", `Basic realm="k8s-metrics-api"`)
+				}
 				http.Error(w, "unauthorized", http.StatusUnauthorized)
 				return
 			}
-			next(w, r)
+			if !id.HasScope(scope) {
+				a.log.WarnContext(r.Context(), "Escopo insuficiente", "path", r.URL.Path, "token", id.Name, "scope", scope)
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			a.log.DebugContext(r.Context(), "Acesso autorizado", "path", r.URL.Path, "token", id.Name)
+			next(w, r.WithContext(WithIdentity(r.Context(), id)))
 		}
 	}
 }
+
+// Erros de credenciais ausentes ou não reconhecidas.
+var (
+	errInvalidToken  = errors.New("token inválido")
+	errInvalidScheme = errors.New("esquema de autenticação ausente ou não aceito")
+)
+
+func (a *Authenticator) authenticate(r *http.Request) (*Identity, error) {
+	scheme, cred, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	scheme = strings.ToLower(scheme)
+	if !a.schemes[scheme] {
+		return nil, errInvalidScheme
+	}
+	switch scheme {
+	case config.AuthSchemeBasic:
+		user, pass, ok := r.BasicAuth()
+		if !ok || (a.basicUsername != "" && subtle.ConstantTimeCompare([]byte(user), []byte(a.basicUsername)) != 1) {
+			return nil, errInvalidToken
+		}
+		if tok, ok := a.tokens.Lookup(pass); ok {
+			return &Identity{Name: tok.Name, Scopes: tok.Scopes, Method: config.AuthSchemeBasic}, nil
+		}
+	default:
+		if strings.Contains(cred, " ") {
+			return nil, errInvalidToken
+		}
+		if tok, ok := a.tokens.Lookup(cred); ok {
+			return &Identity{Name: tok.Name, Scopes: tok.Scopes, Method: config.AuthSchemeBearer}, nil
+		}
+		if a.jwt != nil && strings.Count(cred, ".") == 2 {
+			return a.jwt.Validate(r.Context(), cred)
+		}
+	}
+	return nil, errInvalidToken
+}
+
+// AuthMiddleware valida Bearer token.
+func AuthMiddleware(expectedToken string, logger *slog.Logger) func(http.HandlerFunc) http.HandlerFunc {
+	tokens, _ := config.NewTokenStore("", config.Token{Name: "default", Value: expectedToken, Scopes: []string{config.ScopeAdmin}})
+	return NewAuthenticator(tokens, logger).Require("")
+}
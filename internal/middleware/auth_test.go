@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"bytes"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -8,6 +9,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s-metrics-api/internal/config"
 )
 
 const (
@@ -92,3 +96,158 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticatorRequire(t *testing.T) {
+	tokens, err := config.NewTokenStore("",
+		config.Token{Name: "grafana", Value: "grafana-secret", Scopes: []string{config.ScopeMetricsRead}},
+		config.Token{Name: "ops", Value: "ops-secret", Scopes: []string{config.ScopeAdmin}},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		scope            string
+		headerToken      string
+		expectedStatus   int
+		expectedIdentity string
+	}{
+		{
+			name:             "should allow token with required scope",
+			scope:            config.ScopeMetricsRead,
+			headerToken:      "Bearer grafana-secret",
+			expectedStatus:   http.StatusOK,
+			expectedIdentity: "grafana",
+		},
+		{
+			name:           "should forbid token without required scope",
+			scope:          config.ScopePrometheusScrape,
+			headerToken:    "Bearer grafana-secret",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:             "should allow admin token for any scope",
+			scope:            config.ScopePrometheusScrape,
+			headerToken:      "Bearer ops-secret",
+			expectedStatus:   http.StatusOK,
+			expectedIdentity: "ops",
+		},
+		{
+			name:           "should reject unknown token",
+			scope:          config.ScopeMetricsRead,
+			headerToken:    "Bearer other",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			var identity string
+			testHandler := func(w http.ResponseWriter, r *http.Request) {
+				if id, ok := IdentityFrom(r.Context()); ok {
+					identity = id.Name
+				}
+				w.WriteHeader(http.StatusOK)
+			}
+			wrappedHandler := NewAuthenticator(tokens, logger).Require(tt.scope)(testHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", tt.headerToken)
+			w := httptest.NewRecorder()
+
+			// Act
+			wrappedHandler.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedIdentity, identity)
+		})
+	}
+}
+
+func TestLoggingMiddlewareIncludesToken(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	handler := LoggingMiddleware(logger)(AuthMiddleware(validToken, logger)(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+validToken)
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	assert.Contains(t, buf.String(), `"token":"default"`)
+}
+
+func TestAuthenticatorSchemes(t *testing.T) {
+	tokens, err := config.NewTokenStore("",
+		config.Token{Name: "prometheus", Value: "prom-secret", Scopes: []string{config.ScopePrometheusScrape}},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		opts           []AuthOption
+		setAuth        func(r *http.Request)
+		expectedStatus int
+	}{
+		{
+			name:           "should reject basic credentials when only bearer is accepted",
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("", "prom-secret") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should accept basic credentials with token as password",
+			opts:           []AuthOption{WithSchemes(config.AuthSchemeBearer, config.AuthSchemeBasic)},
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("", "prom-secret") },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should reject basic credentials with wrong password",
+			opts:           []AuthOption{WithSchemes(config.AuthSchemeBasic)},
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("", "wrong") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should require configured basic username",
+			opts:           []AuthOption{WithSchemes(config.AuthSchemeBasic), WithBasicUsername("prometheus")},
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("other", "prom-secret") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should accept configured basic username",
+			opts:           []AuthOption{WithSchemes(config.AuthSchemeBasic), WithBasicUsername("prometheus")},
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("prometheus", "prom-secret") },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should reject bearer when only basic is accepted",
+			opts:           []AuthOption{WithSchemes(config.AuthSchemeBasic)},
+			setAuth:        func(r *http.Request) { r.Header.Set("Authorization", "Bearer prom-secret") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			wrappedHandler := NewAuthenticator(tokens, logger, tt.opts...).Require(config.ScopePrometheusScrape)(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/prometheus", nil)
+			tt.setAuth(req)
+			w := httptest.NewRecorder()
+
+			// Act
+			wrappedHandler.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+
+	"k8s-metrics-api/internal/config"
+)
+
+type ctxKey int
+
+const (
+	identityKey ctxKey = iota
+	stateKey
+	clientIPKey
+)
+
+// Identity descreve o cliente autenticado da requisição.
+type Identity struct {
+	Name   string
+	Scopes []string
+	// Method indica como a identidade foi autenticada (bearer, basic, jwt).
+	Method string
+	// Namespaces permitidos; nil significa acesso a todos.
+	Namespaces []string
+}
+
+// HasScope indica se a identidade possui o escopo. O escopo admin concede todos.
+func (i *Identity) HasScope(scope string) bool {
+	if scope == "" {
+		return true
+	}
+	for _, s := range i.Scopes {
+		if s == scope || s == config.ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsNamespace indica se a identidade pode ver dados do namespace.
+func (i *Identity) AllowsNamespace(ns string) bool {
+	if i.Namespaces == nil {
+		return true
+	}
+	for _, n := range i.Namespaces {
+		if n == ns {
+			return true
+		}
+	}
+	return false
+}
+
+// WithIdentity anexa a identidade ao contexto.
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	if st, ok := ctx.Value(stateKey).(*requestState); ok {
+		st.identity = id
+	}
+	return context.WithValue(ctx, identityKey, id)
+}
+
+// IdentityFrom recupera a identidade autenticada do contexto.
+func IdentityFrom(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey).(*Identity)
+	return id, ok && id != nil
+}
+
+// requestState é compartilhado entre middlewares externos (logging) e internos
+// (auth), já que valores de contexto não sobem pela cadeia de handlers.
+type requestState struct {
+	identity    *Identity
+	authChecked bool
+	// route padrão casado pelo ServeMux, registrado por RouteMiddleware.
+	route string
+}
+
+// withState reaproveita o estado já criado por um middleware mais externo.
+func withState(ctx context.Context) (context.Context, *requestState) {
+	if st, ok := ctx.Value(stateKey).(*requestState); ok {
+		return ctx, st
+	}
+	st := &requestState{}
+	return context.WithValue(ctx, stateKey, st), st
+}
+
+func markAuthChecked(ctx context.Context) {
+	if st, ok := ctx.Value(stateKey).(*requestState); ok {
+		st.authChecked = true
+	}
+}
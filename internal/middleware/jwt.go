@@ -0,0 +1,410 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // registra SHA-256 para crypto.Hash
+	_ "crypto/sha512" // registra SHA-384/512 para crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s-metrics-api/internal/config"
+)
+
+// Erros de validação de JWT.
+var (
+	ErrJWTMalformed   = errors.New("jwt malformado")
+	ErrJWTAlgorithm   = errors.New("algoritmo de jwt não suportado")
+	ErrJWTUnknownKey  = errors.New("chave de assinatura desconhecida")
+	ErrJWTSignature   = errors.New("assinatura de jwt inválida")
+	ErrJWTIssuer      = errors.New("issuer de jwt inválido")
+	ErrJWTAudience    = errors.New("audience de jwt inválida")
+	ErrJWTExpired     = errors.New("jwt expirado")
+	ErrJWTNotYetValid = errors.New("jwt ainda não é válido")
+	ErrJWTNoGroups    = errors.New("nenhum grupo autorizado no jwt")
+)
+
+const (
+	jwtLeeway           = 30 * time.Second
+	jwksRefreshInterval = time.Hour
+	jwksMinRefresh      = time.Minute
+)
+
+// JWTValidator valida JWTs contra o JWKS de um issuer OIDC.
+type JWTValidator struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+	now    func() time.Time
+
+	refreshMu   sync.Mutex
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+	jwksURL     string
+}
+
+// NewJWTValidator cria o validador e carrega o JWKS inicial.
+func NewJWTValidator(ctx context.Context, cfg config.OIDCConfig) (*JWTValidator, error) {
+	v := &JWTValidator{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}, now: time.Now, jwksURL: cfg.JWKSURL}
+	if err := v.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Issuer    string          `json:"iss"`
+	Subject   string          `json:"sub"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+}
+
+// Validate verifica assinatura, issuer, audience e validade do token e
+// retorna a identidade correspondente.
+func (v *JWTValidator) Validate(ctx context.Context, raw string) (*Identity, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+	var hdr jwtHeader
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	hash, err := algHash(hdr.Alg)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	key, err := v.key(ctx, hdr.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(hdr.Alg, hash, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	if claims.Issuer != v.cfg.Issuer {
+		return nil, ErrJWTIssuer
+	}
+	if !audienceContains(claims.Audience, v.cfg.Audience) {
+		return nil, ErrJWTAudience
+	}
+	now := v.now()
+	if claims.ExpiresAt == nil || now.After(unixTime(*claims.ExpiresAt).Add(jwtLeeway)) {
+		return nil, ErrJWTExpired
+	}
+	if claims.NotBefore != nil && now.Add(jwtLeeway).Before(unixTime(*claims.NotBefore)) {
+		return nil, ErrJWTNotYetValid
+	}
+
+	var all map[string]json.RawMessage
+	_ = json.Unmarshal(payload, &all)
+	namespaces, err := v.namespacesFor(stringList(all[v.cfg.GroupsClaim]))
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{Name: claims.Subject, Scopes: v.cfg.Scopes, Namespaces: namespaces, Method: "jwt"}, nil
+}
+
+// namespacesFor resolve os namespaces permitidos a partir dos grupos.
+// Retorna nil quando não há restrição.
+func (v *JWTValidator) namespacesFor(groups []string) ([]string, error) {
+	if len(v.cfg.GroupNamespaces) == 0 {
+		return nil, nil
+	}
+	seen := map[string]bool{}
+	out := []string{}
+	matched := false
+	for _, g := range groups {
+		nss, ok := v.cfg.GroupNamespaces[g]
+		if !ok {
+			continue
+		}
+		matched = true
+		for _, ns := range nss {
+			if ns == "*" {
+				return nil, nil
+			}
+			if !seen[ns] {
+				seen[ns] = true
+				out = append(out, ns)
+			}
+		}
+	}
+	if !matched {
+		return nil, ErrJWTNoGroups
+	}
+	return out, nil
+}
+
+func (v *JWTValidator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	k, ok := lookupKey(v.keys, kid)
+	stale := v.now().Sub(v.lastRefresh) > jwksRefreshInterval
+	canRefresh := v.now().Sub(v.lastRefresh) > jwksMinRefresh
+	v.mu.Unlock()
+	if ok && !stale {
+		return k, nil
+	}
+	if !ok && !canRefresh {
+		return nil, ErrJWTUnknownKey
+	}
+	// Chave desconhecida (rotação no issuer) ou JWKS antigo: recarrega.
+	if err := v.refresh(ctx); err != nil && !ok {
+		return nil, err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if k, ok = lookupKey(v.keys, kid); !ok {
+		return nil, ErrJWTUnknownKey
+	}
+	return k, nil
+}
+
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, true
+		}
+	}
+	k, ok := keys[kid]
+	return k, ok
+}
+
+func (v *JWTValidator) refresh(ctx context.Context) error {
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+	v.mu.Lock()
+	v.lastRefresh = v.now()
+	v.mu.Unlock()
+
+	var (
+		b   []byte
+		err error
+	)
+	if v.cfg.JWKSFile != "" {
+		b, err = os.ReadFile(v.cfg.JWKSFile)
+	} else {
+		b, err = v.fetchJWKS(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao carregar JWKS: %w", err)
+	}
+	keys, err := ParseJWKS(b)
+	if err != nil {
+		return err
+	}
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *JWTValidator) fetchJWKS(ctx context.Context) ([]byte, error) {
+	if v.jwksURL == "" {
+		// Descoberta via metadados OIDC do issuer.
+		var disc struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		b, err := v.get(ctx, strings.TrimSuffix(v.cfg.Issuer, "/")+"/.well-known/openid-configuration")
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &disc); err != nil || disc.JWKSURI == "" {
+			return nil, fmt.Errorf("jwks_uri ausente na descoberta OIDC de %s", v.cfg.Issuer)
+		}
+		v.jwksURL = disc.JWKSURI
+	}
+	return v.get(ctx, v.jwksURL)
+}
+
+func (v *JWTValidator) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS interpreta um documento JWKS com chaves RSA e EC.
+func ParseJWKS(b []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("JWKS inválido: %w", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err1 := decodeBigInt(k.N)
+			e, err2 := decodeBigInt(k.E)
+			if err1 != nil || err2 != nil || !e.IsInt64() {
+				return nil, fmt.Errorf("chave RSA %q inválida", k.Kid)
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			curve := curveFor(k.Crv)
+			x, err1 := decodeBigInt(k.X)
+			y, err2 := decodeBigInt(k.Y)
+			if curve == nil || err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("chave EC %q inválida", k.Kid)
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS sem chaves de assinatura suportadas")
+	}
+	return keys, nil
+}
+
+func algHash(alg string) (crypto.Hash, error) {
+	switch alg {
+	case "RS256", "ES256":
+		return crypto.SHA256, nil
+	case "RS384", "ES384":
+		return crypto.SHA384, nil
+	case "RS512", "ES512":
+		return crypto.SHA512, nil
+	}
+	return 0, ErrJWTAlgorithm
+}
+
+func verifySignature(alg string, hash crypto.Hash, key crypto.PublicKey, signed, sig []byte) error {
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return ErrJWTAlgorithm
+		}
+		if rsa.VerifyPKCS1v15(k, hash, digest, sig) != nil {
+			return ErrJWTSignature
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return ErrJWTAlgorithm
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return ErrJWTSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return ErrJWTSignature
+		}
+	default:
+		return ErrJWTAlgorithm
+	}
+	return nil
+}
+
+func curveFor(crv string) elliptic.Curve {
+	switch crv {
+	case "P-256":
+		return elliptic.P256()
+	case "P-384":
+		return elliptic.P384()
+	case "P-521":
+		return elliptic.P521()
+	}
+	return nil
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, ErrJWTMalformed
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func audienceContains(raw json.RawMessage, aud string) bool {
+	for _, a := range stringList(raw) {
+		if a == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// stringList aceita claims como string única ou lista de strings.
+func stringList(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var one string
+	if json.Unmarshal(raw, &one) == nil {
+		return []string{one}
+	}
+	var many []string
+	_ = json.Unmarshal(raw, &many)
+	return many
+}
+
+func unixTime(f float64) time.Time {
+	return time.Unix(int64(f), 0)
+}
@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s-metrics-api/internal/config"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "k8s-metrics-api"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func rsaJWK(kid string, k *rsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "RSA", "kid": kid, "use": "sig", "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())}
+}
+
+func ecJWK(kid string, k *ecdsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(k.X.FillBytes(make([]byte, 32))), "y": b64(k.Y.FillBytes(make([]byte, 32)))}
+}
+
+func signJWT(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	hdr, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(hdr) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + b64(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":    testIssuer,
+		"sub":    "alice",
+		"aud":    []string{testAudience, "other"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"groups": []string{"team-a"},
+	}
+}
+
+func TestJWTValidator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, _ := json.Marshal(map[string]any{"keys": []any{rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)}})
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	cfg := config.OIDCConfig{
+		Issuer:          testIssuer,
+		Audience:        testAudience,
+		JWKSFile:        jwksFile,
+		GroupsClaim:     "groups",
+		GroupNamespaces: map[string][]string{"team-a": {"app-a", "shared"}, "sre": {"*"}},
+		Scopes:          []string{config.ScopeMetricsRead},
+	}
+	v, err := NewJWTValidator(context.Background(), cfg)
+	require.NoError(t, err)
+
+	with := func(k string, val any) map[string]any {
+		c := validClaims()
+		c[k] = val
+		return c
+	}
+
+	tests := []struct {
+		name               string
+		token              string
+		expectedErr        error
+		expectedNamespaces []string
+	}{
+		{
+			name:               "should accept valid RS256 token",
+			token:              signJWT(t, "RS256", "rsa-1", rsaKey, validClaims()),
+			expectedNamespaces: []string{"app-a", "shared"},
+		},
+		{
+			name:               "should accept valid ES256 token",
+			token:              signJWT(t, "ES256", "ec-1", ecKey, validClaims()),
+			expectedNamespaces: []string{"app-a", "shared"},
+		},
+		{
+			name:  "should grant all namespaces for wildcard group",
+			token: signJWT(t, "RS256", "rsa-1", rsaKey, with("groups", []string{"sre"})),
+		},
+		{
+			name:        "should reject token signed by unknown key",
+			token:       signJWT(t, "RS256", "rsa-1", otherKey, validClaims()),
+			expectedErr: ErrJWTSignature,
+		},
+		{
+			name:        "should reject expired token",
+			token:       signJWT(t, "RS256", "rsa-1", rsaKey, with("exp", time.Now().Add(-time.Hour).Unix())),
+			expectedErr: ErrJWTExpired,
+		},
+		{
+			name:        "should reject token for other audience",
+			token:       signJWT(t, "RS256", "rsa-1", rsaKey, with("aud", "other")),
+			expectedErr: ErrJWTAudience,
+		},
+		{
+			name:        "should reject token from other issuer",
+			token:       signJWT(t, "RS256", "rsa-1", rsaKey, with("iss", "https://evil.example.com")),
+			expectedErr: ErrJWTIssuer,
+		},
+		{
+			name:        "should reject token without mapped groups",
+			token:       signJWT(t, "RS256", "rsa-1", rsaKey, with("groups", []string{"marketing"})),
+			expectedErr: ErrJWTNoGroups,
+		},
+		{
+			name:        "should reject unsigned token",
+			token:       b64([]byte(`{"alg":"none"}`)) + "." + b64([]byte(`{"iss":"x"}`)) + ".",
+			expectedErr: ErrJWTAlgorithm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			id, err := v.Validate(context.Background(), tt.token)
+
+			// Assert
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "alice", id.Name)
+			assert.Equal(t, tt.expectedNamespaces, id.Namespaces)
+			assert.True(t, id.HasScope(config.ScopeMetricsRead))
+		})
+	}
+}
+
+func TestJWTValidatorFetchesJWKSFromURL(t *testing.T) {
+	// Arrange
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []any{rsaJWK("k1", &key.PublicKey)}})
+	}))
+	defer srv.Close()
+	cfg := config.OIDCConfig{Issuer: testIssuer, Audience: testAudience, JWKSURL: srv.URL, GroupsClaim: "groups", Scopes: []string{config.ScopeMetricsRead}}
+
+	// Act
+	v, err := NewJWTValidator(context.Background(), cfg)
+	require.NoError(t, err)
+	id, err := v.Validate(context.Background(), signJWT(t, "RS256", "k1", key, validClaims()))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "alice", id.Name)
+	assert.Nil(t, id.Namespaces)
+}
+
+func TestAuthenticatorWithJWT(t *testing.T) {
+	// Arrange
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, _ := json.Marshal(map[string]any{"keys": []any{rsaJWK("k1", &key.PublicKey)}})
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+	v, err := NewJWTValidator(context.Background(), config.OIDCConfig{
+		Issuer: testIssuer, Audience: testAudience, JWKSFile: jwksFile, GroupsClaim: "groups",
+		GroupNamespaces: map[string][]string{"team-a": {"app-a"}}, Scopes: []string{config.ScopeMetricsRead},
+	})
+	require.NoError(t, err)
+	tokens, _ := config.NewTokenStore("")
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	auth := NewAuthenticator(tokens, logger, WithJWT(v))
+
+	tests := []struct {
+		name           string
+		scope          string
+		claims         map[string]any
+		expectedStatus int
+	}{
+		{name: "should allow valid jwt", scope: config.ScopeMetricsRead, claims: validClaims(), expectedStatus: http.StatusOK},
+		{name: "should forbid jwt without scope", scope: config.ScopePrometheusScrape, claims: validClaims(), expectedStatus: http.StatusForbidden},
+		{name: "should forbid jwt without mapped group", scope: config.ScopeMetricsRead, claims: map[string]any{"iss": testIssuer, "aud": testAudience, "sub": "bob", "exp": time.Now().Add(time.Hour).Unix()}, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var namespaces []string
+			handler := auth.Require(tt.scope)(func(w http.ResponseWriter, r *http.Request) {
+				id, _ := IdentityFrom(r.Context())
+				namespaces = id.Namespaces
+			})
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.Header.Set("Authorization", "Bearer "+signJWT(t, "RS256", "k1", key, tt.claims))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, []string{"app-a"}, namespaces)
+			}
+		})
+	}
+}
@@ -3,24 +3,114 @@ package middleware
 import (
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-// LoggingMiddleware loga requisições.
-func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+// routeUnmatched rota das requisições que não casaram com nenhum padrão.
+const routeUnmatched = "unmatched"
+
+// HTTPMetrics histogramas das requisições HTTP por rota, método e status.
+type HTTPMetrics struct {
+	Duration *prometheus.HistogramVec
+	Size     *prometheus.HistogramVec
+}
+
+// NewHTTPMetrics cria HTTPMetrics e registra suas métricas.
+func NewHTTPMetrics(reg prometheus.Registerer) *HTTPMetrics {
+	labels := []string{"route", "method", "status"}
+	m := &HTTPMetrics{
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "k8s_metrics_api_http_request_duration_seconds",
+			Help:    "Duração das requisições HTTP",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		Size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "k8s_metrics_api_http_response_size_bytes",
+			Help:    "Tamanho das respostas HTTP",
+			Buckets: prometheus.ExponentialBuckets(128, 4, 8),
+		}, labels),
+	}
+	_ = reg.Register(m.Duration) // ignora AlreadyRegistered
+	_ = reg.Register(m.Size)
+	return m
+}
+
+// LoggingOption configura LoggingMiddleware.
+type LoggingOption func(*loggingOptions)
+
+type loggingOptions struct {
+	metrics *HTTPMetrics
+}
+
+// WithHTTPMetrics registra duração e tamanho de cada requisição em m.
+func WithHTTPMetrics(m *HTTPMetrics) LoggingOption {
+	return func(o *loggingOptions) { o.metrics = m }
+}
+
+// LoggingMiddleware loga requisições. A rota (padrão registrado, ex.:
+// "GET /clusters/{name}/metrics") vem de RouteMiddleware ou, sem ele, do
+// Pattern da requisição, quando este middleware envolve diretamente o ServeMux.
+func LoggingMiddleware(logger *slog.Logger, opts ...LoggingOption) func(http.Handler) http.Handler {
+	var o loggingOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			ctx, st := withState(r.Context())
 			rw := &respWriter{ResponseWriter: w, status: 200}
-			next.ServeHTTP(rw, r)
-			logger.Info("http", "method", r.Method, "path", r.URL.Path, "status", rw.status, "dur", time.Since(start))
+			req := r.WithContext(ctx)
+			next.ServeHTTP(rw, req)
+			dur := time.Since(start)
+			attrs := []any{"method", r.Method, "path", r.URL.Path, "status", rw.status, "dur", dur}
+			if st.identity != nil {
+				attrs = append(attrs, "token", st.identity.Name)
+			}
+			logger.InfoContext(ctx, "http", attrs...)
+			if o.metrics != nil {
+				route := st.route
+				if route == "" {
+					route = req.Pattern
+				}
+				if route == "" {
+					route = routeUnmatched
+				}
+				status := strconv.Itoa(rw.status)
+				o.metrics.Duration.WithLabelValues(route, r.Method, status).Observe(dur.Seconds())
+				o.metrics.Size.WithLabelValues(route, r.Method, status).Observe(float64(rw.size))
+			}
 		})
 	}
 }
 
+// RouteMiddleware registra o padrão casado pelo ServeMux no estado
+// compartilhado da requisição. O mux define Pattern na requisição que recebe,
+// e middlewares intermediários (ex.: auditoria) passam cópias feitas com
+// WithContext, que LoggingMiddleware não enxerga. Deve envolver diretamente o
+// ServeMux.
+func RouteMiddleware(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if st, ok := r.Context().Value(stateKey).(*requestState); ok {
+			st.route = r.Pattern
+		}
+	})
+}
+
 type respWriter struct {
 	http.ResponseWriter
 	status int
+	size   int
 }
 
 func (r *respWriter) WriteHeader(code int) { r.status = code; r.ResponseWriter.WriteHeader(code) }
+
+func (r *respWriter) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.size += n
+	return n, err
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingMiddlewareRecordsHTTPMetrics(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		expectedRoute string
+		expectedCode  string
+		expectedSize  float64
+	}{
+		{
+			name:          "should label requests with the matched pattern",
+			path:          "/clusters/east/metrics",
+			expectedRoute: "GET /clusters/{name}/metrics",
+			expectedCode:  "200",
+			expectedSize:  4,
+		},
+		{
+			name:          "should label unmatched requests",
+			path:          "/nope",
+			expectedRoute: routeUnmatched,
+			expectedCode:  "404",
+			expectedSize:  19,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			m := NewHTTPMetrics(prometheus.NewRegistry())
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /clusters/{name}/metrics", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("east"))
+			})
+			logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+			handler := LoggingMiddleware(logger, WithHTTPMetrics(m))(mux)
+
+			// Act
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, 1, testutil.CollectAndCount(m.Duration))
+			var size dto.Metric
+			require.NoError(t, m.Size.WithLabelValues(tt.expectedRoute, http.MethodGet, tt.expectedCode).(prometheus.Metric).Write(&size))
+			assert.Equal(t, uint64(1), size.GetHistogram().GetSampleCount())
+			assert.Equal(t, tt.expectedSize, size.GetHistogram().GetSampleSum())
+		})
+	}
+}
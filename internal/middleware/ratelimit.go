@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"k8s-metrics-api/internal/config"
+)
+
+// Motivos de limitação reportados em k8s_metrics_api_throttled_requests_total.
+const (
+	throttleIdentity    = "identity"
+	throttleIP          = "ip"
+	throttleConcurrency = "concurrency"
+)
+
+const limiterIdleTTL = 10 * time.Minute
+
+// RateLimiter aplica token buckets por identidade e por IP e limita as
+// coletas simultâneas contra a API do Kubernetes.
+type RateLimiter struct {
+	cfg       config.RateLimitConfig
+	log       *slog.Logger
+	sem       chan struct{}
+	Throttled *prometheus.CounterVec
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	lim      *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter cria RateLimiter e registra suas métricas.
+func NewRateLimiter(cfg config.RateLimitConfig, reg prometheus.Registerer, logger *slog.Logger) *RateLimiter {
+	l := &RateLimiter{
+		cfg:     cfg,
+		log:     logger,
+		buckets: map[string]*bucket{},
+		Throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "k8s_metrics_api_throttled_requests_total",
+			Help: "Requisições rejeitadas com 429 por motivo",
+		}, []string{"reason"}),
+	}
+	if cfg.MaxConcurrentCollections > 0 {
+		l.sem = make(chan struct{}, cfg.MaxConcurrentCollections)
+	}
+	_ = reg.Register(l.Throttled) // ignora AlreadyRegistered
+	return l
+}
+
+// Limit aplica o limite por identidade autenticada. Deve ser encadeado após
+// Authenticator.Require para enxergar a identidade.
+func (l *RateLimiter) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := IdentityFrom(r.Context()); ok && l.cfg.IdentityRPS > 0 {
+			if wait := l.reserve("id:"+id.Name, l.cfg.IdentityRPS, l.cfg.IdentityBurst, time.Now()); wait > 0 {
+				l.reject(w, r, throttleIdentity, wait)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// LimitIP aplica o limite por IP de origem a todas as requisições, antes da
+// autenticação, para que credenciais ausentes ou inválidas também consumam o
+// bucket. Deve envolver o ServeMux, dentro do RealIPMiddleware.
+func (l *RateLimiter) LimitIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.cfg.IPRPS > 0 {
+			if wait := l.reserve("ip:"+ClientIP(r), l.cfg.IPRPS, l.cfg.IPBurst, time.Now()); wait > 0 {
+				l.reject(w, r, throttleIP, wait)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitConcurrency limita quantas coletas executam ao mesmo tempo.
+func (l *RateLimiter) LimitConcurrency(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if l.sem == nil {
+			next(w, r)
+			return
+		}
+		select {
+		case l.sem <- struct{}{}:
+			defer func() { <-l.sem }()
+			next(w, r)
+		default:
+			l.reject(w, r, throttleConcurrency, time.Second)
+		}
+	}
+}
+
+// reserve consome um token do bucket da chave e retorna quanto o cliente
+// deveria esperar; zero significa que a requisição foi aceita.
+func (l *RateLimiter) reserve(key string, rps float64, burst int, now time.Time) time.Duration {
+	if burst < 1 {
+		burst = 1
+	}
+	l.mu.Lock()
+	if now.Sub(l.lastSweep) > time.Minute {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > limiterIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{lim: rate.NewLimiter(rate.Limit(rps), burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	l.mu.Unlock()
+
+	res := b.lim.ReserveN(now, 1)
+	if !res.OK() {
+		return time.Second
+	}
+	delay := res.DelayFrom(now)
+	if delay > 0 {
+		res.CancelAt(now)
+	}
+	return delay
+}
+
+func (l *RateLimiter) reject(w http.ResponseWriter, r *http.Request, reason string, wait time.Duration) {
+	l.Throttled.WithLabelValues(reason).Inc()
+	l.log.WarnContext(r.Context(), "Requisição limitada", "path", r.URL.Path, "reason", reason, "retryAfter", wait)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	http.Error(w, "too many requests", http.StatusTooManyRequests)
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"k8s-metrics-api/internal/config"
+)
+
+func TestRateLimiterLimit(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            config.RateLimitConfig
+		identities     []string
+		remoteAddrs    []string
+		expectedStatus []int
+		expectedReason string
+	}{
+		{
+			name:           "should throttle identity after burst",
+			cfg:            config.RateLimitConfig{IdentityRPS: 0.001, IdentityBurst: 2},
+			identities:     []string{"grafana", "grafana", "grafana"},
+			remoteAddrs:    []string{"10.0.0.1:1", "10.0.0.2:1", "10.0.0.3:1"},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			expectedReason: throttleIdentity,
+		},
+		{
+			name:           "should keep separate buckets per identity",
+			cfg:            config.RateLimitConfig{IdentityRPS: 0.001, IdentityBurst: 1},
+			identities:     []string{"grafana", "ci"},
+			remoteAddrs:    []string{"10.0.0.1:1", "10.0.0.1:2"},
+			expectedStatus: []int{http.StatusOK, http.StatusOK},
+		},
+		{
+			name:           "should throttle ip after burst",
+			cfg:            config.RateLimitConfig{IPRPS: 0.001, IPBurst: 1},
+			identities:     []string{"grafana", "ci"},
+			remoteAddrs:    []string{"10.0.0.1:1", "10.0.0.1:2"},
+			expectedStatus: []int{http.StatusOK, http.StatusTooManyRequests},
+			expectedReason: throttleIP,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			l := NewRateLimiter(tt.cfg, prometheus.NewRegistry(), logger)
+			handler := l.LimitIP(l.Limit(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+
+			for i, want := range tt.expectedStatus {
+				req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+				req.RemoteAddr = tt.remoteAddrs[i]
+				req = req.WithContext(WithIdentity(req.Context(), &Identity{Name: tt.identities[i]}))
+				w := httptest.NewRecorder()
+
+				// Act
+				handler.ServeHTTP(w, req)
+
+				// Assert
+				assert.Equal(t, want, w.Code, "request %d", i)
+				if want == http.StatusTooManyRequests {
+					assert.NotEmpty(t, w.Header().Get("Retry-After"))
+				}
+			}
+			if tt.expectedReason != "" {
+				assert.Equal(t, 1.0, testutil.ToFloat64(l.Throttled.WithLabelValues(tt.expectedReason)))
+			}
+		})
+	}
+}
+
+func TestRateLimiterLimitConcurrency(t *testing.T) {
+	// Arrange
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	l := NewRateLimiter(config.RateLimitConfig{MaxConcurrentCollections: 1}, prometheus.NewRegistry(), logger)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := l.LimitConcurrency(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var wg sync.WaitGroup
+	first := httptest.NewRecorder()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	}()
+	<-started
+
+	// Act
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	close(release)
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	assert.Equal(t, "1", second.Header().Get("Retry-After"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(l.Throttled.WithLabelValues(throttleConcurrency)))
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s-metrics-api/internal/config"
+	"k8s-metrics-api/internal/logging"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		incoming    string
+		expectKeep  bool
+		expectedLen int
+	}{
+		{name: "should honor incoming id", incoming: "abc-123", expectKeep: true},
+		{name: "should generate id when missing", expectedLen: 32},
+		{name: "should replace unsafe id", incoming: "bad id\nforged=1", expectedLen: 32},
+		{name: "should replace oversized id", incoming: strings.Repeat("a", 129), expectedLen: 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var fromCtx string
+			handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fromCtx = logging.RequestID(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(w, req)
+
+			// Assert
+			got := w.Header().Get(RequestIDHeader)
+			assert.Equal(t, got, fromCtx)
+			if tt.expectKeep {
+				assert.Equal(t, tt.incoming, got)
+			} else {
+				assert.Len(t, got, tt.expectedLen)
+			}
+		})
+	}
+}
+
+func TestRequestIDInLogs(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logging.FormatJSON, nil)
+	tokens, err := config.NewTokenStore("", config.Token{Name: "grafana", Value: "secret", Scopes: []string{config.ScopeMetricsRead}})
+	require.NoError(t, err)
+	auth := NewAuthenticator(tokens, logger)
+	handler := RequestIDMiddleware(LoggingMiddleware(logger)(auth.Require(config.ScopeMetricsRead)(func(w http.ResponseWriter, r *http.Request) {})))
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	req.Header.Set("Authorization", "Bearer wrong")
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.Equal(t, "req-42", entry["requestId"], line)
+	}
+}